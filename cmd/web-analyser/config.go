@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/limiter"
+	"github.com/DiLRandI/web-analyser/internal/service/webpage"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	storeMemory = "memory"
+	storeSqlite = "sqlite"
+)
+
+func getApplicationPort() string {
+	return getEnv("APP_PORT", "8080")
+}
+
+// getStore returns the result store backend, either `memory` or `sqlite`.
+func getStore() string {
+	return getEnv("APP_STORE", storeMemory)
+}
+
+// getMemoryCapacity returns the maximum number of results kept by the memory store, 0 means unbounded.
+func getMemoryCapacity() int {
+	return getEnvInt("APP_MEMORY_CAPACITY", 1000)
+}
+
+// getWorkers returns the number of analyses processed concurrently.
+func getWorkers() int {
+	return getEnvInt("APP_WORKERS", 4)
+}
+
+func getSqlitePath() string {
+	return getEnv("APP_SQLITE_PATH", "web-analyser.db")
+}
+
+// getClientConfig returns the policies of the http client used to download the pages and check the links.
+// `APP_HTTP_HEADERS` is a comma separated list of `Name=value` pairs.
+func getClientConfig() *webpage.ClientConfig {
+	headers := map[string]string{}
+	for _, h := range strings.Split(os.Getenv("APP_HTTP_HEADERS"), ",") {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(h, "=")
+		if !ok {
+			log.Fatalf("`APP_HTTP_HEADERS` entry %q is not in the Name=value format", h)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return &webpage.ClientConfig{
+		Timeout:         getEnvDuration("APP_HTTP_TIMEOUT", 15*time.Second),
+		UserAgent:       getEnv("APP_HTTP_USER_AGENT", fmt.Sprintf("web-analyser/%s", Version)),
+		Headers:         headers,
+		ProxyUrl:        os.Getenv("APP_HTTP_PROXY"),
+		MaxResponseSize: int64(getEnvInt("APP_HTTP_MAX_RESPONSE_SIZE", 10<<20)),
+	}
+}
+
+// getLimiterConfig returns the limits of the outgoing requests, shared by all the running analyses.
+func getLimiterConfig() *limiter.Config {
+	rate, err := strconv.ParseFloat(getEnv("APP_HTTP_RATE_PER_HOST", "5"), 64)
+	if err != nil {
+		log.Fatalf("`APP_HTTP_RATE_PER_HOST` must be a number, %v", err)
+	}
+
+	return &limiter.Config{
+		RatePerHost:   rate,
+		BurstPerHost:  getEnvInt("APP_HTTP_BURST_PER_HOST", 5),
+		MaxConcurrent: getEnvInt("APP_HTTP_MAX_CONCURRENT", 32),
+	}
+}
+
+// getRespectRobots returns whether the robots.txt rules and crawl delays of the sites are honoured.
+func getRespectRobots() bool {
+	return getEnvBool("APP_ROBOTS", true)
+}
+
+// getMaxCrawlDelay returns the upper bound of the Crawl-delay honoured between two requests to a host.
+func getMaxCrawlDelay() time.Duration {
+	return getEnvDuration("APP_ROBOTS_MAX_CRAWL_DELAY", 10*time.Second)
+}
+
+// getLinkCacheTtl returns how long a checked link status is reused by the analyses, 0 disables the cache.
+func getLinkCacheTtl() time.Duration {
+	return getEnvDuration("APP_LINK_CACHE_TTL", 5*time.Minute)
+}
+
+// getCheckResources returns whether the resources of the pages are checked like their links.
+func getCheckResources() bool {
+	return getEnvBool("APP_CHECK_RESOURCES", false)
+}
+
+// getFormKeywords returns the keywords classifying the forms, the JSON file of APP_FORM_KEYWORDS_FILE
+// maps the form classes to keywords added to the defaults.
+func getFormKeywords() webpage.FormKeywords {
+	path := os.Getenv("APP_FORM_KEYWORDS_FILE")
+	if path == "" {
+		return webpage.DefaultFormKeywords()
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("unable to read the form keywords file %q, %v", path, err)
+	}
+
+	extra := webpage.FormKeywords{}
+	if err := json.Unmarshal(content, &extra); err != nil {
+		log.Fatalf("`APP_FORM_KEYWORDS_FILE` must be a JSON object of keyword lists by form class, %v", err)
+	}
+
+	return webpage.DefaultFormKeywords().With(extra)
+}
+
+func getEnv(key, defaultValue string) string {
+	v := os.Getenv(key)
+	if v == "" {
+		log.Warnf("`%s` not specified defaulting to %q", key, defaultValue)
+		v = defaultValue
+	}
+
+	return v
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	v := getEnv(key, strconv.Itoa(defaultValue))
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("`%s` must be an integer, %v", key, err)
+	}
+
+	return i
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	v := getEnv(key, strconv.FormatBool(defaultValue))
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("`%s` must be true or false, %v", key, err)
+	}
+
+	return b
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	v := getEnv(key, defaultValue.String())
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Fatalf("`%s` must be a duration such as 15s, %v", key, err)
+	}
+
+	return d
+}
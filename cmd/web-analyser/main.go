@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/DiLRandI/web-analyser/internal/app/handler"
 	"github.com/DiLRandI/web-analyser/internal/repository"
 	"github.com/DiLRandI/web-analyser/internal/repository/mem"
+	"github.com/DiLRandI/web-analyser/internal/repository/sqlite"
 	"github.com/DiLRandI/web-analyser/internal/service"
+	"github.com/DiLRandI/web-analyser/internal/service/job"
+	"github.com/DiLRandI/web-analyser/internal/service/limiter"
 	"github.com/DiLRandI/web-analyser/internal/service/webpage"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -23,9 +31,33 @@ func main() {
 	di := initializeDi()
 	registerHandlers(router, di)
 
-	if err := router.Run(fmt.Sprintf(":%s", appPort)); err != nil {
-		log.Fatalf("Unable to start the server on port %s, %v", appPort, err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := di.processor.Start(context.Background()); err != nil {
+		log.Fatalf("Unable to start the processor, %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%s", appPort),
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
 	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Unable to start the server on port %s, %v", appPort, err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Infof("Shutting down web-analyser")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Errorf("Unable to shutdown the server gracefully, %v", err)
+	}
+
+	di.processor.Stop()
 }
 
 func CORSMiddleware() gin.HandlerFunc {
@@ -35,7 +67,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, "+
 			"Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, "+
 			"X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Total-Count")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -51,15 +84,34 @@ func registerHandlers(router *gin.Engine, di *diRegistry) {
 }
 
 func initializeDi() *diRegistry {
-	resultRepo := mem.NewResultInMemory()
-	downloader := webpage.NewDownloader(http.DefaultClient)
+	resultRepo, jobRepo, crawlRepo := newRepositories()
+	clientConfig := getClientConfig()
+	webClient, err := webpage.NewWebClient(clientConfig)
+	if err != nil {
+		log.Fatalf("Unable to create the web client, %v", err)
+	}
+
+	// the limiter comes before the robots.txt check, the robots.txt downloads are limited too
+	webClient = webpage.NewLimitedClient(webClient, limiter.New(getLimiterConfig()))
+	if getRespectRobots() {
+		webClient = webpage.NewRobotsClient(webClient, clientConfig.UserAgent, getMaxCrawlDelay())
+	}
+
+	downloader := webpage.NewDownloader(webClient)
+	// the link checker is shared, the analyses and crawls reuse the statuses of the links checked recently
+	linkChecker := webpage.NewLinkChecker(webClient, getLinkCacheTtl())
+	resourcesCheck := webpage.NewResourcesCheck(linkChecker, getCheckResources())
+	formKeywords := getFormKeywords()
 	analyserFn := func() webpage.Analyser {
-		return webpage.NewAnalyser(http.DefaultClient)
+		return webpage.NewAnalyser(linkChecker, formKeywords, resourcesCheck)
 	}
-	processor := service.NewProcessor(downloader, analyserFn, resultRepo)
+	queue := job.NewQueue(jobRepo, getWorkers())
+	processor := service.NewProcessor(downloader, analyserFn, resultRepo, crawlRepo, queue)
 
 	return &diRegistry{
 		resultRepo:    resultRepo,
+		jobRepo:       jobRepo,
+		crawlRepo:     crawlRepo,
 		downloaderSvc: downloader,
 		processor:     processor,
 
@@ -69,18 +121,29 @@ func initializeDi() *diRegistry {
 
 type diRegistry struct {
 	resultRepo    repository.Results
+	jobRepo       repository.Jobs
+	crawlRepo     repository.Crawls
 	downloaderSvc webpage.Downloader
 	processor     service.Processor
 
 	analyserFn func() webpage.Analyser
 }
 
-func getApplicationPort() string {
-	p := os.Getenv("APP_PORT")
-	if p == "" {
-		log.Warnf("Application port `APP_PORT` not specified defaulting to 8080")
-		p = "8080"
-	}
+func newRepositories() (repository.Results, repository.Jobs, repository.Crawls) {
+	switch store := getStore(); store {
+	case storeMemory:
+		return mem.NewBoundedResultInMemory(getMemoryCapacity()), mem.NewJobInMemory(), mem.NewCrawlInMemory()
+	case storeSqlite:
+		path := getSqlitePath()
+		log.Infof("Using sqlite result store %q", path)
+		db, err := sqlite.Open(context.Background(), path)
+		if err != nil {
+			log.Fatalf("Unable to open the sqlite store, %v", err)
+		}
 
-	return p
+		return sqlite.NewResultSqlite(db), sqlite.NewJobSqlite(db), sqlite.NewCrawlSqlite(db)
+	default:
+		log.Fatalf("Unknown result store `APP_STORE` %q, expected %q or %q", store, storeMemory, storeSqlite)
+		return nil, nil, nil
+	}
 }
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/DiLRandI/web-analyser/internal/dto"
 	"github.com/DiLRandI/web-analyser/internal/service"
@@ -11,6 +12,8 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const totalCountHeader = "X-Total-Count"
+
 type analysisHandler struct {
 	processor service.Processor
 }
@@ -26,6 +29,11 @@ func (h *analysisHandler) RegisterRoutes(router *gin.Engine) {
 	apiV1.POST("analyse", h.analyse)
 	apiV1.GET("analyse", h.getAnalysis)
 	apiV1.GET("analyse/:id", h.getAnalysisById)
+	apiV1.GET("analyse/:id/links", h.getAnalysisLinks)
+	apiV1.DELETE("analyse/:id", h.deleteAnalysis)
+	apiV1.POST("analyse/:id/cancel", h.cancelAnalysis)
+	apiV1.POST("crawl", h.crawl)
+	apiV1.GET("crawl/:id", h.getCrawlById)
 }
 
 func (h *analysisHandler) analyse(c *gin.Context) {
@@ -45,53 +53,284 @@ func (h *analysisHandler) analyse(c *gin.Context) {
 
 	res, err := h.processor.ProcessPage(c.Request.Context(), req)
 	if err != nil {
-		logrus.Errorf("error while trying to process the page, %v", err)
-		c.AbortWithStatus(http.StatusInternalServerError)
+		abortWithServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusAccepted, res)
 }
 
+// getAnalysis responds with a page of the analysed reports, the number of reports matching the filters
+// is in the X-Total-Count header.
+// Query params: status, url (substring of the url or host), from and to (RFC3339 requested time range),
+// hasLoginForm, sort (requested|completed), order (asc|desc, default desc), page, limit.
 func (h *analysisHandler) getAnalysis(c *gin.Context) {
+	pagination, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	q := &dto.ResultsQuery{
+		Pagination:    pagination,
+		ProcessStatus: c.Query("status"),
+		Url:           c.Query("url"),
+		SortBy:        dto.SortByRequested,
+		Descending:    true,
+	}
+
+	for _, param := range []struct {
+		name  string
+		value **time.Time
+	}{
+		{name: "from", value: &q.RequestedFrom},
+		{name: "to", value: &q.RequestedTo},
+	} {
+		v := c.Query(param.name)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			logrus.Errorf("Unable to parse query %s %q as RFC3339 time, %v", param.name, v, err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		*param.value = &t
+	}
+
+	if v := c.Query("hasLoginForm"); v != "" {
+		hasLoginForm, err := strconv.ParseBool(v)
+		if err != nil {
+			logrus.Errorf("Unable to parse query hasLoginForm %q to bool, %v", v, err)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		q.HasLoginForm = &hasLoginForm
+	}
+
+	switch sortBy := c.DefaultQuery("sort", dto.SortByRequested); sortBy {
+	case dto.SortByRequested, dto.SortByCompleted:
+		q.SortBy = sortBy
+	default:
+		logrus.Errorf("Invalid query sort %q", sortBy)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	switch order := c.DefaultQuery("order", "desc"); order {
+	case "asc", "desc":
+		q.Descending = order == "desc"
+	default:
+		logrus.Errorf("Invalid query order %q", order)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
 	log.Infof("Retrieving analysed reports")
-	res, err := h.processor.GetProcessResults(c)
+	res, total, err := h.processor.GetProcessResults(c.Request.Context(), q)
 	if err != nil {
 		logrus.Error(err)
 		c.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
+	c.Header(totalCountHeader, strconv.Itoa(total))
 	c.JSON(http.StatusOK, res)
 }
 
 func (h *analysisHandler) getAnalysisById(c *gin.Context) {
-	paramId := c.Param("id")
-	if paramId == "" {
-		c.AbortWithStatus(http.StatusBadRequest)
+	id, ok := parseIdParam(c)
+	if !ok {
 		return
 	}
 
-	id, err := strconv.ParseInt(paramId, 10, 64)
+	log.Infof("Retrieving analysed report for id %d", id)
+	res, err := h.processor.GetProcessResultFor(c.Request.Context(), id)
 	if err != nil {
-		logrus.Errorf("Unable to parse parameter id %q to int, %v", paramId, err)
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// getAnalysisLinks responds with a page of the links found by the analysis, the number of links
+// matching the filters is in the X-Total-Count header.
+// Query params: status (Active|Inactive|BlockedByRobots|NotChecked), type (internal|external), httpStatusCode,
+// page, limit.
+func (h *analysisHandler) getAnalysisLinks(c *gin.Context) {
+	id, ok := parseIdParam(c)
+	if !ok {
+		return
+	}
+
+	pagination, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	q := &dto.LinksQuery{Pagination: pagination, Status: c.Query("status")}
+
+	switch typ := c.Query("type"); typ {
+	case "":
+	case "internal", "external":
+		isInternal := typ == "internal"
+		q.IsInternal = &isInternal
+	default:
+		logrus.Errorf("Invalid link type %q", typ)
 		c.AbortWithStatus(http.StatusBadRequest)
 		return
 	}
 
-	log.Infof("Retrieving analysed report for id %d", id)
-	res, err := h.processor.GetProcessResultFor(c.Request.Context(), id)
-	if err != nil {
-		if notFoundErr, ok := err.(*service.NotFoundError); ok {
-			logrus.Error(notFoundErr)
-			c.AbortWithStatus(http.StatusNotFound)
+	if code := c.Query("httpStatusCode"); code != "" {
+		httpStatusCode, err := strconv.Atoi(code)
+		if err != nil {
+			logrus.Errorf("Unable to parse query httpStatusCode %q to int, %v", code, err)
+			c.AbortWithStatus(http.StatusBadRequest)
 			return
 		}
+		q.HttpStatusCode = &httpStatusCode
+	}
 
-		logrus.Error(err)
-		c.AbortWithStatus(http.StatusInternalServerError)
+	log.Infof("Retrieving links of analysed report for id %d", id)
+	res, total, err := h.processor.GetProcessLinksFor(c.Request.Context(), id, q)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Header(totalCountHeader, strconv.Itoa(total))
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *analysisHandler) deleteAnalysis(c *gin.Context) {
+	id, ok := parseIdParam(c)
+	if !ok {
+		return
+	}
+
+	log.Infof("Deleting analysed report for id %d", id)
+	if err := h.processor.RemoveProcessResult(c.Request.Context(), id); err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *analysisHandler) cancelAnalysis(c *gin.Context) {
+	id, ok := parseIdParam(c)
+	if !ok {
+		return
+	}
+
+	log.Infof("Cancelling analysis for id %d", id)
+	if err := h.processor.CancelProcess(c.Request.Context(), id); err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// crawl queues a crawl of the site, body: webUrl and the optional maxDepth and maxPages limits.
+func (h *analysisHandler) crawl(c *gin.Context) {
+	log.Infof("Processing crawl request")
+	req := &dto.CrawlRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		log.Errorf("Invalid request, %v", err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	if req.WebUrl == "" {
+		log.Error("WebURL is empty")
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.processor.ProcessSite(c.Request.Context(), req)
+	if err != nil {
+		abortWithServiceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func (h *analysisHandler) getCrawlById(c *gin.Context) {
+	id, ok := parseIdParam(c)
+	if !ok {
+		return
+	}
+
+	log.Infof("Retrieving site report for crawl id %d", id)
+	res, err := h.processor.GetSiteResultFor(c.Request.Context(), id)
+	if err != nil {
+		abortWithServiceError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, res)
 }
+
+// parseIdParam reads the `:id` url param, it aborts with bad request when the param is not a valid id.
+func parseIdParam(c *gin.Context) (int64, bool) {
+	paramId := c.Param("id")
+	if paramId == "" {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(paramId, 10, 64)
+	if err != nil {
+		logrus.Errorf("Unable to parse parameter id %q to int, %v", paramId, err)
+		c.AbortWithStatus(http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// parsePagination reads the `page` and `limit` query params, it aborts with bad request when they are invalid.
+func parsePagination(c *gin.Context) (dto.Pagination, bool) {
+	p := dto.Pagination{Page: 1, Limit: dto.DefaultPageLimit}
+	for _, param := range []struct {
+		name  string
+		value *int
+		max   int
+	}{
+		{name: "page", value: &p.Page, max: dto.MaxPage},
+		{name: "limit", value: &p.Limit, max: dto.MaxPageLimit},
+	} {
+		v := c.Query(param.name)
+		if v == "" {
+			continue
+		}
+
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 || (param.max > 0 && i > param.max) {
+			logrus.Errorf("Invalid query param %s %q", param.name, v)
+			c.AbortWithStatus(http.StatusBadRequest)
+			return p, false
+		}
+		*param.value = i
+	}
+
+	return p, true
+}
+
+func abortWithServiceError(c *gin.Context, err error) {
+	logrus.Error(err)
+	switch err.(type) {
+	case *service.ValidationError:
+		c.AbortWithStatus(http.StatusBadRequest)
+	case *service.NotFoundError:
+		c.AbortWithStatus(http.StatusNotFound)
+	case *service.ConflictError:
+		c.AbortWithStatus(http.StatusConflict)
+	default:
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}
+}
@@ -46,6 +46,34 @@ func Test_handler_validations(t *testing.T) {
 			payload:       strings.NewReader(`{}`),
 			expStatusCode: http.StatusBadRequest,
 		},
+		{
+			desc:          "getAnalysis handler respond with bad request when from is not a valid time",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse?from=yesterday",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysis handler respond with bad request when hasLoginForm is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse?hasLoginForm=maybe",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysis handler respond with bad request when sort is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse?sort=title",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysis handler respond with bad request when order is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse?order=up",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
 		{
 			desc:          "getAnalysisById handler respond with bad request when url param id is not valid",
 			httpMethod:    http.MethodGet,
@@ -53,6 +81,83 @@ func Test_handler_validations(t *testing.T) {
 			payload:       nil,
 			expStatusCode: http.StatusBadRequest,
 		},
+		{
+			desc:          "getAnalysisLinks handler respond with bad request when url param id is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/abc/links",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysisLinks handler respond with bad request when type is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/1/links?type=abc",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysisLinks handler respond with bad request when httpStatusCode is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/1/links?httpStatusCode=abc",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysisLinks handler respond with bad request when page is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/1/links?page=0",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysis handler respond with bad request when page is above the maximum",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse?page=9223372036854775807&limit=500",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getAnalysisLinks handler respond with bad request when limit is above the maximum",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/1/links?limit=100000",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "deleteAnalysis handler respond with bad request when url param id is not valid",
+			httpMethod:    http.MethodDelete,
+			endpoint:      "/api/v1/analyse/abc",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "cancelAnalysis handler respond with bad request when url param id is not valid",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/analyse/abc/cancel",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "crawl handler respond with bad request for invalid body",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/crawl",
+			payload:       strings.NewReader(""),
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "crawl handler respond with bad request when WebURL is empty",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/crawl",
+			payload:       strings.NewReader(`{"maxDepth":1}`),
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getCrawlById handler respond with bad request when url param id is not valid",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/crawl/abc",
+			payload:       nil,
+			expStatusCode: http.StatusBadRequest,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -86,6 +191,13 @@ func Test_handler_service_error(t *testing.T) {
 			payload:       strings.NewReader(`{"webUrl":"https://www.test.com/"}`),
 			expStatusCode: http.StatusInternalServerError,
 		},
+		{
+			desc:          "analyse handler respond with bad request for validation service error",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/analyse",
+			payload:       strings.NewReader(`{"webUrl":"/foo"}`),
+			expStatusCode: http.StatusBadRequest,
+		},
 		{
 			desc:          "getAalyse handler respond with internal server error for service error",
 			httpMethod:    http.MethodGet,
@@ -107,6 +219,55 @@ func Test_handler_service_error(t *testing.T) {
 			payload:       nil,
 			expStatusCode: http.StatusNotFound,
 		},
+		{
+			desc:          "getAnalysisLinks handler respond not found for id 2 not found service error",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/analyse/2/links",
+			payload:       nil,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:          "deleteAnalysis handler respond internal server error for id 1 generic service error",
+			httpMethod:    http.MethodDelete,
+			endpoint:      "/api/v1/analyse/1",
+			payload:       nil,
+			expStatusCode: http.StatusInternalServerError,
+		},
+		{
+			desc:          "deleteAnalysis handler respond not found for id 2 not found service error",
+			httpMethod:    http.MethodDelete,
+			endpoint:      "/api/v1/analyse/2",
+			payload:       nil,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:          "cancelAnalysis handler respond not found for id 2 not found service error",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/analyse/2/cancel",
+			payload:       nil,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			desc:          "cancelAnalysis handler respond conflict for id 3 already finished service error",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/analyse/3/cancel",
+			payload:       nil,
+			expStatusCode: http.StatusConflict,
+		},
+		{
+			desc:          "crawl handler respond with bad request for validation service error",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/crawl",
+			payload:       strings.NewReader(`{"webUrl":"https://www.test.com/","maxPages":100000}`),
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "getCrawlById handler respond not found for id 2 not found service error",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/crawl/2",
+			payload:       nil,
+			expStatusCode: http.StatusNotFound,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -114,6 +275,8 @@ func Test_handler_service_error(t *testing.T) {
 			routeEng := gin.Default()
 
 			mp := new(mc.ProcessorMock)
+			mp.On("ProcessPage", mock.Anything, &dto.AnalysesRequest{WebUrl: "/foo"}).
+				Return((*dto.AnalysesResponse)(nil), &service.ValidationError{})
 			mp.On("ProcessPage", mock.Anything, mock.Anything).
 				Return((*dto.AnalysesResponse)(nil), errors.New("service failing"))
 				//GetProcessResultFor id 1 generic error return
@@ -122,8 +285,22 @@ func Test_handler_service_error(t *testing.T) {
 				//GetProcessResultFor id 2 not found error return
 			mp.On("GetProcessResultFor", mock.Anything, int64(2)).
 				Return((*dto.ResultResponse)(nil), &service.NotFoundError{})
-			mp.On("GetProcessResults", mock.Anything).
-				Return(([]*dto.ResultResponse)(nil), errors.New("service failing"))
+			mp.On("GetProcessResults", mock.Anything, mock.Anything).
+				Return(([]*dto.ResultResponse)(nil), 0, errors.New("service failing"))
+			mp.On("GetProcessLinksFor", mock.Anything, int64(2), mock.Anything).
+				Return(([]*dto.LinkResponse)(nil), 0, &service.NotFoundError{})
+			mp.On("RemoveProcessResult", mock.Anything, int64(1)).
+				Return(errors.New("service failing"))
+			mp.On("RemoveProcessResult", mock.Anything, int64(2)).
+				Return(&service.NotFoundError{})
+			mp.On("CancelProcess", mock.Anything, int64(2)).
+				Return(&service.NotFoundError{})
+			mp.On("CancelProcess", mock.Anything, int64(3)).
+				Return(&service.ConflictError{})
+			mp.On("ProcessSite", mock.Anything, mock.Anything).
+				Return((*dto.AnalysesResponse)(nil), &service.ValidationError{})
+			mp.On("GetSiteResultFor", mock.Anything, int64(2)).
+				Return((*dto.CrawlResponse)(nil), &service.NotFoundError{})
 
 			sut := New(mp)
 			sut.RegisterRoutes(routeEng)
@@ -211,8 +388,28 @@ func Test_handler_happy_path(t *testing.T) {
 		},
 	}
 
+	crawlRes := &dto.CrawlResponse{
+		Id:                1,
+		Url:               "https://www.test.com/",
+		Requested:         now,
+		Completed:         &completed,
+		ProcessStatus:     "Completed",
+		MaxDepth:          2,
+		MaxPages:          50,
+		PageCount:         2,
+		AnalysedPageCount: 2,
+		OrphanedPages:     []string{"https://www.test.com/old"},
+		UnreachablePages:  []string{},
+		Pages: []*dto.CrawlPageResponse{
+			{Url: "https://www.test.com/", AnalysisId: 1, Reachable: true, ProcessStatus: "Completed"},
+			{Url: "https://www.test.com/old", AnalysisId: 2, Orphaned: true, Reachable: true,
+				ProcessStatus: "Completed"},
+		},
+	}
+
 	res1Json, _ := json.Marshal(res1)
 	res2Json, _ := json.Marshal(res2)
+	crawlResJson, _ := json.Marshal(crawlRes)
 
 	testCases := []struct {
 		desc          string
@@ -246,6 +443,36 @@ func Test_handler_happy_path(t *testing.T) {
 			expStatusCode: http.StatusOK,
 			expResponse:   string(res1Json),
 		},
+		{
+			desc:          "deleteAnalysis handler respond with no content for id 1",
+			httpMethod:    http.MethodDelete,
+			endpoint:      "/api/v1/analyse/1",
+			payload:       nil,
+			expStatusCode: http.StatusNoContent,
+		},
+		{
+			desc:          "cancelAnalysis handler respond with accepted for id 1",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/analyse/1/cancel",
+			payload:       nil,
+			expStatusCode: http.StatusAccepted,
+		},
+		{
+			desc:          "crawl handler respond with accepted and the crawl id",
+			httpMethod:    http.MethodPost,
+			endpoint:      "/api/v1/crawl",
+			payload:       strings.NewReader(`{"webUrl":"https://www.test.com/","maxDepth":2}`),
+			expStatusCode: http.StatusAccepted,
+			expResponse:   "{\"id\":1}",
+		},
+		{
+			desc:          "getCrawlById handler respond with the site report for id 1",
+			httpMethod:    http.MethodGet,
+			endpoint:      "/api/v1/crawl/1",
+			payload:       nil,
+			expStatusCode: http.StatusOK,
+			expResponse:   string(crawlResJson),
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -258,8 +485,16 @@ func Test_handler_happy_path(t *testing.T) {
 			mp.On("GetProcessResultFor", mock.Anything, int64(1)).
 				Return(res1, nil)
 				//GetProcessResultFor id 2 not found error return
-			mp.On("GetProcessResults", mock.Anything).
-				Return(res2, nil)
+			mp.On("GetProcessResults", mock.Anything, mock.Anything).
+				Return(res2, len(res2), nil)
+			mp.On("RemoveProcessResult", mock.Anything, int64(1)).
+				Return(nil)
+			mp.On("CancelProcess", mock.Anything, int64(1)).
+				Return(nil)
+			mp.On("ProcessSite", mock.Anything, &dto.CrawlRequest{WebUrl: "https://www.test.com/", MaxDepth: 2}).
+				Return(&dto.AnalysesResponse{Id: 1}, nil)
+			mp.On("GetSiteResultFor", mock.Anything, int64(1)).
+				Return(crawlRes, nil)
 
 			sut := New(mp)
 			sut.RegisterRoutes(routeEng)
@@ -274,3 +509,72 @@ func Test_handler_happy_path(t *testing.T) {
 		})
 	}
 }
+
+func Test_handler_get_analysis_links(t *testing.T) {
+	isInternal := false
+	notFound := http.StatusNotFound
+	links := []*dto.LinkResponse{
+		{
+			Name:           "Broken",
+			Url:            "https://www.external.com/broken",
+			IsInternal:     false,
+			LinkStatus:     "Inactive",
+			HttpStatusCode: http.StatusNotFound,
+		},
+	}
+	linksJson, _ := json.Marshal(links)
+
+	w := httptest.NewRecorder()
+	routeEng := gin.Default()
+	mp := new(mc.ProcessorMock)
+	mp.On("GetProcessLinksFor", mock.Anything, int64(1), &dto.LinksQuery{
+		Pagination:     dto.Pagination{Page: 2, Limit: 10},
+		Status:         "Inactive",
+		IsInternal:     &isInternal,
+		HttpStatusCode: &notFound,
+	}).Return(links, 11, nil)
+
+	sut := New(mp)
+	sut.RegisterRoutes(routeEng)
+	req, _ := http.NewRequest(http.MethodGet,
+		"/api/v1/analyse/1/links?status=Inactive&type=external&httpStatusCode=404&page=2&limit=10", nil)
+	routeEng.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "11", w.Header().Get("X-Total-Count"))
+
+	res, err := io.ReadAll(w.Result().Body)
+	assert.NoError(t, err)
+	assert.Equal(t, string(linksJson), string(res))
+}
+
+func Test_handler_get_analysis_query(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	hasLoginForm := true
+
+	w := httptest.NewRecorder()
+	routeEng := gin.Default()
+	mp := new(mc.ProcessorMock)
+	mp.On("GetProcessResults", mock.Anything, &dto.ResultsQuery{
+		Pagination:    dto.Pagination{Page: 3, Limit: 20},
+		ProcessStatus: "Completed",
+		Url:           "test.com",
+		RequestedFrom: &from,
+		RequestedTo:   &to,
+		HasLoginForm:  &hasLoginForm,
+		SortBy:        dto.SortByCompleted,
+		Descending:    false,
+	}).Return([]*dto.ResultResponse{}, 42, nil)
+
+	sut := New(mp)
+	sut.RegisterRoutes(routeEng)
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/analyse?status=Completed&url=test.com"+
+		"&from=2023-01-01T00:00:00Z&to=2023-02-01T00:00:00Z&hasLoginForm=true"+
+		"&sort=completed&order=asc&page=3&limit=20", nil)
+	routeEng.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "42", w.Header().Get("X-Total-Count"))
+	mp.AssertExpectations(t)
+}
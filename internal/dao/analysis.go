@@ -1,6 +1,10 @@
 package dao
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 type Analyses struct {
 	Id                int64
@@ -8,20 +12,123 @@ type Analyses struct {
 	Requested         time.Time
 	Completed         *time.Time
 	ProcessStatus     *ProcessStatus
+	FinalUrl          string
+	Redirects         []*Redirect
+	StatusCode        int
+	Headers           map[string][]string
 	Title             string
 	Headings          map[string]int
 	InternalLinkCount int
 	ExternalLinkCount int
 	ActiveLinkCount   int
 	InactiveLinkCount int
+	BlockedLinkCount  int
+	LinkTypeCounts    map[string]int
 	PageVersion       string
 	HasLoginForm      bool
+	// Sections are the JSON encoded findings of the analyser checks, by check name.
+	Sections map[string]json.RawMessage
+	Links    []*Link
 }
 
 type ProcessStatus string
 
 var (
 	ProcessStatusCreated   ProcessStatus = "Created"
+	ProcessStatusQueued    ProcessStatus = "Queued"
+	ProcessStatusRunning   ProcessStatus = "Running"
 	ProcessStatusCompleted ProcessStatus = "Completed"
 	ProcessStatusFailed    ProcessStatus = "Failed"
+	ProcessStatusCancelled ProcessStatus = "Cancelled"
+	ProcessStatusBlocked   ProcessStatus = "BlockedByRobots"
 )
+
+type Redirect struct {
+	Url        string
+	StatusCode int
+	Location   string
+}
+
+type Link struct {
+	Name string
+	// Url is the href of the link as written in the document, ResolvedUrl is the absolute url it points to.
+	Url            string
+	ResolvedUrl    string
+	Type           string
+	IsInternal     bool
+	LinkStatus     string
+	HttpStatusCode int
+}
+
+// LinkQuery filters the links of an analysis, nil / empty fields do not filter.
+// Limit <= 0 returns all the matching links.
+type LinkQuery struct {
+	LinkStatus     string
+	IsInternal     *bool
+	HttpStatusCode *int
+	Offset         int
+	Limit          int
+}
+
+func (q *LinkQuery) Match(l *Link) bool {
+	if q.LinkStatus != "" && q.LinkStatus != l.LinkStatus {
+		return false
+	}
+
+	if q.IsInternal != nil && *q.IsInternal != l.IsInternal {
+		return false
+	}
+
+	if q.HttpStatusCode != nil && *q.HttpStatusCode != l.HttpStatusCode {
+		return false
+	}
+
+	return true
+}
+
+type AnalysesSort string
+
+var (
+	AnalysesSortRequested AnalysesSort = "requested"
+	AnalysesSortCompleted AnalysesSort = "completed"
+)
+
+// AnalysesQuery filters, sorts and pages the analyses, nil / empty fields do not filter.
+// Limit <= 0 returns all the matching analyses.
+type AnalysesQuery struct {
+	ProcessStatus string
+	// UrlContains matches a case insensitive substring of the url, which includes the host.
+	UrlContains   string
+	RequestedFrom *time.Time
+	RequestedTo   *time.Time
+	HasLoginForm  *bool
+	// SortBy defaults to AnalysesSortRequested, analyses which are not completed sort last by completion.
+	SortBy     AnalysesSort
+	Descending bool
+	Offset     int
+	Limit      int
+}
+
+func (q *AnalysesQuery) Match(m *Analyses) bool {
+	if q.ProcessStatus != "" && (m.ProcessStatus == nil || string(*m.ProcessStatus) != q.ProcessStatus) {
+		return false
+	}
+
+	if q.UrlContains != "" && !strings.Contains(strings.ToLower(m.Url), strings.ToLower(q.UrlContains)) {
+		return false
+	}
+
+	if q.RequestedFrom != nil && m.Requested.Before(*q.RequestedFrom) {
+		return false
+	}
+
+	if q.RequestedTo != nil && m.Requested.After(*q.RequestedTo) {
+		return false
+	}
+
+	if q.HasLoginForm != nil && *q.HasLoginForm != m.HasLoginForm {
+		return false
+	}
+
+	return true
+}
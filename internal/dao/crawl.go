@@ -0,0 +1,35 @@
+package dao
+
+import "time"
+
+// Crawl is a site crawl, it analyses the pages reachable through the internal links of the start url.
+type Crawl struct {
+	Id            int64
+	Url           string
+	Requested     time.Time
+	Completed     *time.Time
+	ProcessStatus *ProcessStatus
+	MaxDepth      int
+	MaxPages      int
+	// Pages are in the order they were discovered, each page refers to its own analysis.
+	Pages []*CrawlPage
+}
+
+// CrawlPage is a page found by the crawl with a summary of its analysis.
+type CrawlPage struct {
+	Url        string
+	Depth      int
+	AnalysisId int64
+	// InboundLinkCount is the number of links to the page from the other crawled pages.
+	InboundLinkCount int
+	// Orphaned pages are listed in the sitemap of the site but no crawled page links to them.
+	Orphaned          bool
+	ProcessStatus     *ProcessStatus
+	StatusCode        int
+	Title             string
+	InternalLinkCount int
+	ExternalLinkCount int
+	ActiveLinkCount   int
+	InactiveLinkCount int
+	HasLoginForm      bool
+}
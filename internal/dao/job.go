@@ -0,0 +1,21 @@
+package dao
+
+import "time"
+
+// Job is a unit of background work waiting in the job queue.
+type Job struct {
+	Id   int64
+	Type JobType
+	// TargetId is the id of the record the job produces, the analysis for JobTypeAnalysis
+	// and the crawl for JobTypeCrawl.
+	TargetId int64
+	Url      string
+	Created  time.Time
+}
+
+type JobType string
+
+var (
+	JobTypeAnalysis JobType = "Analysis"
+	JobTypeCrawl    JobType = "Crawl"
+)
@@ -0,0 +1,52 @@
+package dto
+
+import "time"
+
+type CrawlRequest struct {
+	WebUrl string `json:"webUrl"`
+	// MaxDepth is the number of links followed from the start url, 0 uses the default depth.
+	MaxDepth int `json:"maxDepth"`
+	// MaxPages is the maximum number of pages analysed, 0 uses the default.
+	MaxPages int `json:"maxPages"`
+}
+
+type CrawlResponse struct {
+	Id            int64      `json:"id"`
+	Url           string     `json:"url"`
+	Requested     time.Time  `json:"requested"`
+	Completed     *time.Time `json:"completed"`
+	ProcessStatus string     `json:"processStatus"`
+	MaxDepth      int        `json:"maxDepth"`
+	MaxPages      int        `json:"maxPages"`
+	// PageCount is the number of pages found, AnalysedPageCount the ones with a completed analysis.
+	PageCount         int `json:"pageCount"`
+	AnalysedPageCount int `json:"analysedPageCount"`
+	// the link counts are the sums over the analysed pages
+	InternalLinkCount  int `json:"internalLinkCount"`
+	ExternalLinkCount  int `json:"externalLinkCount"`
+	ActiveLinkCount    int `json:"activeLinkCount"`
+	InactiveLinkCount  int `json:"inactiveLinkCount"`
+	LoginFormPageCount int `json:"loginFormPageCount"`
+	// OrphanedPages are listed in the sitemap of the site but no crawled page links to them.
+	OrphanedPages []string `json:"orphanedPages"`
+	// UnreachablePages failed to download or responded with an error status code.
+	UnreachablePages []string             `json:"unreachablePages"`
+	Pages            []*CrawlPageResponse `json:"pages"`
+}
+
+type CrawlPageResponse struct {
+	Url               string `json:"url"`
+	Depth             int    `json:"depth"`
+	AnalysisId        int64  `json:"analysisId"`
+	InboundLinkCount  int    `json:"inboundLinkCount"`
+	Orphaned          bool   `json:"orphaned"`
+	Reachable         bool   `json:"reachable"`
+	ProcessStatus     string `json:"processStatus"`
+	StatusCode        int    `json:"statusCode"`
+	Title             string `json:"title"`
+	InternalLinkCount int    `json:"internalLinkCount"`
+	ExternalLinkCount int    `json:"externalLinkCount"`
+	ActiveLinkCount   int    `json:"activeLinkCount"`
+	InactiveLinkCount int    `json:"inactiveLinkCount"`
+	HasLoginForm      bool   `json:"hasLoginForm"`
+}
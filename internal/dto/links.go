@@ -0,0 +1,19 @@
+package dto
+
+type LinkResponse struct {
+	Name           string `json:"name"`
+	Url            string `json:"url"`
+	ResolvedUrl    string `json:"resolvedUrl"`
+	Type           string `json:"type"`
+	IsInternal     bool   `json:"isInternal"`
+	LinkStatus     string `json:"linkStatus"`
+	HttpStatusCode int    `json:"httpStatusCode"`
+}
+
+// LinksQuery filters the links of an analysis, nil / empty fields do not filter.
+type LinksQuery struct {
+	Pagination
+	Status         string
+	IsInternal     *bool
+	HttpStatusCode *int
+}
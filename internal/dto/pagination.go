@@ -0,0 +1,19 @@
+package dto
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+	// MaxPage keeps the offset of the last page far from overflowing an int.
+	MaxPage = 1000000
+)
+
+// Pagination selects a page of a result set, Page starts from 1.
+type Pagination struct {
+	Page  int
+	Limit int
+}
+
+// Offset returns the number of items skipped before the page.
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
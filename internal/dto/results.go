@@ -1,19 +1,55 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type ResultResponse struct {
-	Id                int64          `json:"id"`
-	Url               string         `json:"url"`
-	Requested         time.Time      `json:"requested"`
-	Completed         *time.Time     `json:"completed"`
-	ProcessStatus     string         `json:"processStatus"`
-	Title             string         `json:"title"`
-	Headings          map[string]int `json:"headings"`
-	InternalLinkCount int            `json:"internalLinkCount"`
-	ExternalLinkCount int            `json:"externalLinkCount"`
-	ActiveLinkCount   int            `json:"activeLinkCount"`
-	InactiveLinkCount int            `json:"inactiveLinkCount"`
-	PageVersion       string         `json:"pageVersion"`
-	HasLoginForm      bool           `json:"hasLoginForm"`
+	Id                int64               `json:"id"`
+	Url               string              `json:"url"`
+	Requested         time.Time           `json:"requested"`
+	Completed         *time.Time          `json:"completed"`
+	ProcessStatus     string              `json:"processStatus"`
+	FinalUrl          string              `json:"finalUrl"`
+	Redirects         []*RedirectResponse `json:"redirects"`
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string][]string `json:"headers"`
+	Title             string              `json:"title"`
+	Headings          map[string]int      `json:"headings"`
+	InternalLinkCount int                 `json:"internalLinkCount"`
+	ExternalLinkCount int                 `json:"externalLinkCount"`
+	ActiveLinkCount   int                 `json:"activeLinkCount"`
+	InactiveLinkCount int                 `json:"inactiveLinkCount"`
+	BlockedLinkCount  int                 `json:"blockedLinkCount"`
+	LinkTypeCounts    map[string]int      `json:"linkTypeCounts"`
+	PageVersion       string              `json:"pageVersion"`
+	HasLoginForm      bool                `json:"hasLoginForm"`
+	// Sections are the findings of the analyser checks, by check name.
+	Sections map[string]json.RawMessage `json:"sections"`
+}
+
+type RedirectResponse struct {
+	Url        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+	Location   string `json:"location"`
+}
+
+const (
+	SortByRequested = "requested"
+	SortByCompleted = "completed"
+)
+
+// ResultsQuery filters, sorts and pages the analysis results, nil / empty fields do not filter.
+type ResultsQuery struct {
+	Pagination
+	ProcessStatus string
+	// Url matches a case insensitive substring of the analysed url, which includes the host.
+	Url           string
+	RequestedFrom *time.Time
+	RequestedTo   *time.Time
+	HasLoginForm  *bool
+	// SortBy is either SortByRequested or SortByCompleted.
+	SortBy     string
+	Descending bool
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+)
+
+// Crawls persists the site crawls, the analyses of the crawled pages are in the Results store.
+type Crawls interface {
+	Save(ctx context.Context, m *dao.Crawl) (int64, error)
+	Update(ctx context.Context, id int64, m *dao.Crawl) error
+	Remove(ctx context.Context, id int64) error
+	Get(ctx context.Context, id int64) (*dao.Crawl, error)
+}
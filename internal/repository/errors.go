@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"errors"
+)
+
+var (
+	ResultNotFoundErr = errors.New("Results not found for given id")
+	JobNotFoundErr    = errors.New("Job not found for given id")
+	CrawlNotFoundErr  = errors.New("Crawl not found for given id")
+)
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+)
+
+// Jobs persists the pending jobs of the job queue, a job is removed once it is finished.
+type Jobs interface {
+	Save(ctx context.Context, j *dao.Job) (int64, error)
+	Remove(ctx context.Context, id int64) error
+	// GetAll returns the pending jobs in the order they were saved.
+	GetAll(ctx context.Context) ([]*dao.Job, error)
+}
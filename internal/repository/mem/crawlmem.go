@@ -0,0 +1,86 @@
+package mem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+)
+
+type crawlInMem struct {
+	mu        sync.Mutex
+	currentId int64
+	data      map[int64]*dao.Crawl
+}
+
+// NewCrawlInMemory returns an unbounded in memory crawl store.
+func NewCrawlInMemory() repository.Crawls {
+	return &crawlInMem{
+		data: make(map[int64]*dao.Crawl),
+	}
+}
+
+func (r *crawlInMem) Save(ctx context.Context, m *dao.Crawl) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.currentId++
+	item := copyCrawl(m)
+	item.Id = r.currentId
+	r.data[item.Id] = item
+
+	return item.Id, nil
+}
+
+func (r *crawlInMem) Update(ctx context.Context, id int64, m *dao.Crawl) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[id]; !ok {
+		return CrawlNotFoundErr
+	}
+
+	item := copyCrawl(m)
+	item.Id = id
+	r.data[id] = item
+	return nil
+}
+
+func (r *crawlInMem) Remove(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[id]; !ok {
+		return CrawlNotFoundErr
+	}
+
+	delete(r.data, id)
+	return nil
+}
+
+func (r *crawlInMem) Get(ctx context.Context, id int64) (*dao.Crawl, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item, ok := r.data[id]
+	if !ok {
+		return nil, CrawlNotFoundErr
+	}
+
+	return copyCrawl(item), nil
+}
+
+// copyCrawl copies the item so callers never share the pages held by the store.
+func copyCrawl(m *dao.Crawl) *dao.Crawl {
+	item := *m
+	if m.Pages != nil {
+		item.Pages = make([]*dao.CrawlPage, len(m.Pages))
+		for i, p := range m.Pages {
+			page := *p
+			item.Pages[i] = &page
+		}
+	}
+
+	return &item
+}
@@ -1,9 +1,11 @@
 package mem
 
 import (
-	"errors"
+	"github.com/DiLRandI/web-analyser/internal/repository"
 )
 
 var (
-	ResultNotFoundErr = errors.New("Results not found for given id")
+	ResultNotFoundErr = repository.ResultNotFoundErr
+	JobNotFoundErr    = repository.JobNotFoundErr
+	CrawlNotFoundErr  = repository.CrawlNotFoundErr
 )
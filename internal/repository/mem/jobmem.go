@@ -0,0 +1,64 @@
+package mem
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+)
+
+type jobInMem struct {
+	mu        sync.Mutex
+	currentId int64
+	data      map[int64]*dao.Job
+}
+
+// NewJobInMemory returns a job store which does not survive a restart.
+func NewJobInMemory() repository.Jobs {
+	return &jobInMem{
+		data: make(map[int64]*dao.Job),
+	}
+}
+
+func (r *jobInMem) Save(ctx context.Context, j *dao.Job) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.currentId++
+	item := *j
+	item.Id = r.currentId
+	r.data[item.Id] = &item
+
+	return item.Id, nil
+}
+
+func (r *jobInMem) Remove(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.data[id]; !ok {
+		return JobNotFoundErr
+	}
+
+	delete(r.data, id)
+	return nil
+}
+
+func (r *jobInMem) GetAll(ctx context.Context) ([]*dao.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	jobs := []*dao.Job{}
+	for _, j := range r.data {
+		item := *j
+		jobs = append(jobs, &item)
+	}
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Id < jobs[k].Id
+	})
+
+	return jobs, nil
+}
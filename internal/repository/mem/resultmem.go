@@ -1,71 +1,260 @@
 package mem
 
 import (
+	"container/list"
 	"context"
-	"sync/atomic"
+	"encoding/json"
+	"sort"
+	"sync"
 
 	"github.com/DiLRandI/web-analyser/internal/dao"
 	"github.com/DiLRandI/web-analyser/internal/repository"
 )
 
-var data map[int64]*dao.Analyses = make(map[int64]*dao.Analyses)
-var currentId int64 = 0
-
 type resultInMem struct {
+	mu        sync.Mutex
+	capacity  int
+	currentId int64
+	data      map[int64]*list.Element
+	// recent orders the items from the most recently used (front) to the least recently used (back)
+	recent *list.List
 }
 
+// NewResultInMemory returns an unbounded in memory store.
 func NewResultInMemory() repository.Results {
-	return &resultInMem{}
+	return NewBoundedResultInMemory(0)
 }
 
-func nextId() int64 {
-	return atomic.AddInt64(&currentId, 1)
+// NewBoundedResultInMemory returns an in memory store holding at most capacity items,
+// when the store is full the least recently used item is evicted. capacity <= 0 means unbounded.
+func NewBoundedResultInMemory(capacity int) repository.Results {
+	return &resultInMem{
+		capacity: capacity,
+		data:     make(map[int64]*list.Element),
+		recent:   list.New(),
+	}
 }
 
 func (r *resultInMem) Save(ctx context.Context, m *dao.Analyses) (int64, error) {
-	id := nextId()
-	item := *m
-	item.Id = id
-	data[id] = &item
-	return id, nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.currentId++
+	item := copyAnalyses(m)
+	item.Id = r.currentId
+	r.data[item.Id] = r.recent.PushFront(item)
+	r.evict()
+
+	return item.Id, nil
 }
 
 func (r *resultInMem) Update(ctx context.Context, id int64, m *dao.Analyses) error {
-	_, ok := data[id]
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.data[id]
 	if !ok {
 		return ResultNotFoundErr
 	}
 
-	item := *m
+	item := copyAnalyses(m)
 	item.Id = id
-	data[id] = &item
+	e.Value = item
+	r.recent.MoveToFront(e)
+	// the store goes over its capacity while the analyses are in progress
+	r.evict()
 	return nil
 }
 
 func (r *resultInMem) Remove(ctx context.Context, id int64) error {
-	if _, ok := data[id]; !ok {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.data[id]
+	if !ok {
 		return ResultNotFoundErr
 	}
 
-	delete(data, id)
+	r.recent.Remove(e)
+	delete(r.data, id)
 	return nil
 }
 
 func (r *resultInMem) Get(ctx context.Context, id int64) (*dao.Analyses, error) {
-	if _, ok := data[id]; !ok {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.data[id]
+	if !ok {
 		return nil, ResultNotFoundErr
 	}
-	item := *data[id]
-	return &item, nil
+
+	r.recent.MoveToFront(e)
+	return copyAnalyses(e.Value.(*dao.Analyses)), nil
 }
 
 func (r *resultInMem) GetAll(ctx context.Context) ([]*dao.Analyses, error) {
-	results := []*dao.Analyses{}
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	for _, d := range data {
-		item := *d
-		results = append(results, &item)
+	results := []*dao.Analyses{}
+	for _, e := range r.data {
+		results = append(results, copyAnalyses(e.Value.(*dao.Analyses)))
 	}
 
 	return results, nil
 }
+
+func (r *resultInMem) Query(ctx context.Context, q *dao.AnalysesQuery) ([]*dao.Analyses, int, error) {
+	r.mu.Lock()
+	matches := []*dao.Analyses{}
+	for _, e := range r.data {
+		m := e.Value.(*dao.Analyses)
+		if q.Match(m) {
+			item := copyAnalyses(m)
+			item.Links = nil
+			matches = append(matches, item)
+		}
+	}
+	r.mu.Unlock()
+
+	sort.Slice(matches, func(i, k int) bool {
+		return less(q, matches[i], matches[k])
+	})
+
+	return page(matches, q.Offset, q.Limit), len(matches), nil
+}
+
+func (r *resultInMem) GetLinks(ctx context.Context, id int64, q *dao.LinkQuery) ([]*dao.Link, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.data[id]
+	if !ok {
+		return nil, 0, ResultNotFoundErr
+	}
+
+	matches := []*dao.Link{}
+	for _, l := range e.Value.(*dao.Analyses).Links {
+		if q.Match(l) {
+			link := *l
+			matches = append(matches, &link)
+		}
+	}
+
+	return page(matches, q.Offset, q.Limit), len(matches), nil
+}
+
+// evict drops the least recently used finished items until the store fits in its capacity, the queued
+// and running items are kept so their jobs can save the results. Must be called holding the lock.
+func (r *resultInMem) evict() {
+	if r.capacity <= 0 {
+		return
+	}
+
+	for e := r.recent.Back(); e != nil && r.recent.Len() > r.capacity; {
+		prev := e.Prev()
+		if item := e.Value.(*dao.Analyses); !inProgress(item) {
+			r.recent.Remove(e)
+			delete(r.data, item.Id)
+		}
+		e = prev
+	}
+}
+
+func inProgress(m *dao.Analyses) bool {
+	if m.ProcessStatus == nil {
+		return false
+	}
+
+	switch *m.ProcessStatus {
+	case dao.ProcessStatusCreated, dao.ProcessStatusQueued, dao.ProcessStatusRunning:
+		return true
+	}
+
+	return false
+}
+
+// copyAnalyses copies the item so callers never share the maps and slices held by the store.
+func copyAnalyses(m *dao.Analyses) *dao.Analyses {
+	item := *m
+	if m.Redirects != nil {
+		item.Redirects = make([]*dao.Redirect, len(m.Redirects))
+		for i, r := range m.Redirects {
+			redirect := *r
+			item.Redirects[i] = &redirect
+		}
+	}
+
+	if m.Headers != nil {
+		item.Headers = make(map[string][]string, len(m.Headers))
+		for k, v := range m.Headers {
+			item.Headers[k] = append([]string(nil), v...)
+		}
+	}
+
+	if m.Headings != nil {
+		item.Headings = make(map[string]int, len(m.Headings))
+		for k, v := range m.Headings {
+			item.Headings[k] = v
+		}
+	}
+
+	if m.LinkTypeCounts != nil {
+		item.LinkTypeCounts = make(map[string]int, len(m.LinkTypeCounts))
+		for k, v := range m.LinkTypeCounts {
+			item.LinkTypeCounts[k] = v
+		}
+	}
+
+	if m.Sections != nil {
+		item.Sections = make(map[string]json.RawMessage, len(m.Sections))
+		for k, v := range m.Sections {
+			item.Sections[k] = append(json.RawMessage(nil), v...)
+		}
+	}
+
+	if m.Links != nil {
+		item.Links = make([]*dao.Link, len(m.Links))
+		for i, l := range m.Links {
+			link := *l
+			item.Links[i] = &link
+		}
+	}
+
+	return &item
+}
+
+// less orders the analyses by the query sort key, missing completion times sort last and ties
+// are broken by id, the same way the sqlite store does it.
+func less(q *dao.AnalysesQuery, a, b *dao.Analyses) bool {
+	ta, tb := &a.Requested, &b.Requested
+	if q.SortBy == dao.AnalysesSortCompleted {
+		ta, tb = a.Completed, b.Completed
+	}
+
+	switch {
+	case ta == nil && tb == nil:
+	case ta == nil:
+		return false
+	case tb == nil:
+		return true
+	case !ta.Equal(*tb):
+		return ta.Before(*tb) != q.Descending
+	}
+
+	return (a.Id < b.Id) != q.Descending
+}
+
+func page[T any](items []T, offset, limit int) []T {
+	if offset < 0 || offset >= len(items) {
+		return []T{}
+	}
+
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items
+}
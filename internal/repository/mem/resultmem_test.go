@@ -2,38 +2,35 @@ package mem
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DiLRandI/web-analyser/internal/dao"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_save_should_add_item_to_map(t *testing.T) {
-	t.Cleanup(cleanup)
-	sut := NewResultInMemory()
-	assert.Empty(t, data)
+	sut := NewResultInMemory().(*resultInMem)
+	assert.Empty(t, sut.data)
 
 	id, err := sut.Save(context.Background(), &dao.Analyses{})
 
 	assert.NoError(t, err)
 	assert.Greater(t, id, int64(0))
-	assert.NotEmpty(t, data)
-	assert.Len(t, data, 1)
+	assert.NotEmpty(t, sut.data)
+	assert.Len(t, sut.data, 1)
 
-	_, ok := data[id]
+	_, ok := sut.data[id]
 	assert.True(t, ok)
 }
 
 func Test_update_should_update_the_values_in_map(t *testing.T) {
-	t.Cleanup(cleanup)
-	data[1] = &dao.Analyses{
-		Id:    1,
+	sut := newStoreWith(0, &dao.Analyses{
 		Title: "test",
 		Url:   "https://www.test.com",
-	}
-	currentId = 1
-	sut := NewResultInMemory()
-	assert.NotEmpty(t, data)
+	})
+	assert.NotEmpty(t, sut.data)
 
 	updateModel := &dao.Analyses{
 		Id:    1,
@@ -43,15 +40,12 @@ func Test_update_should_update_the_values_in_map(t *testing.T) {
 	err := sut.Update(context.Background(), 1, updateModel)
 
 	assert.NoError(t, err)
-	assert.Equal(t, data[1], updateModel)
+	assert.Equal(t, sut.data[1].Value, updateModel)
 }
 
 func Test_update_should_throw_an_error_for_invalid_id(t *testing.T) {
-	t.Cleanup(cleanup)
-	data[1] = &dao.Analyses{}
-	currentId = 1
+	sut := newStoreWith(0, &dao.Analyses{})
 
-	sut := NewResultInMemory()
 	invalidId := int64(2)
 	err := sut.Update(context.Background(), invalidId, &dao.Analyses{})
 
@@ -59,51 +53,45 @@ func Test_update_should_throw_an_error_for_invalid_id(t *testing.T) {
 }
 
 func Test_save_should_persis_two_consecutive_items(t *testing.T) {
-	t.Cleanup(cleanup)
-	sut := NewResultInMemory()
-	assert.Empty(t, data)
+	sut := NewResultInMemory().(*resultInMem)
+	assert.Empty(t, sut.data)
 
 	id1, err1 := sut.Save(context.Background(), &dao.Analyses{})
 
 	assert.NoError(t, err1)
 	assert.Greater(t, id1, int64(0))
-	assert.NotEmpty(t, data)
-	assert.Len(t, data, 1)
+	assert.NotEmpty(t, sut.data)
+	assert.Len(t, sut.data, 1)
 
-	_, ok1 := data[id1]
+	_, ok1 := sut.data[id1]
 	assert.True(t, ok1)
 
 	id2, err2 := sut.Save(context.Background(), &dao.Analyses{})
 
 	assert.NoError(t, err2)
 	assert.Greater(t, id2, int64(1))
-	assert.NotEmpty(t, data)
-	assert.Len(t, data, 2)
+	assert.NotEmpty(t, sut.data)
+	assert.Len(t, sut.data, 2)
 
-	_, ok2 := data[id2]
+	_, ok2 := sut.data[id2]
 	assert.True(t, ok2)
 
 }
 
 func Test_remove_should_remove_the_item_from_map(t *testing.T) {
-	t.Cleanup(cleanup)
-	data[1] = &dao.Analyses{}
-	currentId = 1
-	sut := NewResultInMemory()
-	assert.NotEmpty(t, data)
+	sut := newStoreWith(0, &dao.Analyses{})
+	assert.NotEmpty(t, sut.data)
 
 	err := sut.Remove(context.Background(), 1)
 
 	assert.NoError(t, err)
-	assert.Empty(t, data)
+	assert.Empty(t, sut.data)
+	assert.Zero(t, sut.recent.Len())
 }
 
 func Test_remove_should_remove_throw_an_error_for_invalid_id(t *testing.T) {
-	t.Cleanup(cleanup)
-	data[1] = &dao.Analyses{}
-	currentId = 1
+	sut := newStoreWith(0, &dao.Analyses{})
 
-	sut := NewResultInMemory()
 	invalidId := int64(2)
 	err := sut.Remove(context.Background(), invalidId)
 
@@ -111,12 +99,9 @@ func Test_remove_should_remove_throw_an_error_for_invalid_id(t *testing.T) {
 }
 
 func Test_get_should_return_item_on_map(t *testing.T) {
-	t.Cleanup(cleanup)
-	s := &dao.Analyses{}
-	data[1] = s
-	currentId = 1
+	s := &dao.Analyses{Id: 1}
+	sut := newStoreWith(0, s)
 
-	sut := NewResultInMemory()
 	result, err := sut.Get(context.Background(), 1)
 
 	assert.NoError(t, err)
@@ -125,37 +110,196 @@ func Test_get_should_return_item_on_map(t *testing.T) {
 }
 
 func Test_get_should_throw_an_error_for_invalid_id(t *testing.T) {
-	t.Cleanup(cleanup)
-	s := &dao.Analyses{}
-	data[1] = s
-	currentId = 1
+	sut := newStoreWith(0, &dao.Analyses{})
 
 	invalidId := int64(2)
-	sut := NewResultInMemory()
 	result, err := sut.Get(context.Background(), invalidId)
 
 	assert.ErrorIs(t, err, ResultNotFoundErr)
 	assert.Nil(t, result)
 }
 
+func Test_get_should_not_share_state_with_the_store(t *testing.T) {
+	sut := newStoreWith(0, &dao.Analyses{Headings: map[string]int{"h1": 1}})
+
+	result, err := sut.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	result.Headings["h1"] = 10
+
+	stored, err := sut.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stored.Headings["h1"])
+}
+
 func Test_get_all_return_all_the_items_in_map(t *testing.T) {
-	t.Cleanup(cleanup)
-	data[1] = &dao.Analyses{}
-	data[2] = &dao.Analyses{}
-	data[3] = &dao.Analyses{}
-	currentId = 3
+	sut := newStoreWith(0, &dao.Analyses{}, &dao.Analyses{}, &dao.Analyses{})
 
-	sut := NewResultInMemory()
 	results, err := sut.GetAll(context.Background())
 
 	assert.NoError(t, err)
 	assert.Len(t, results, 3)
 }
 
-func cleanup() {
-	currentId = 0
-	for k := range data {
-		delete(data, k)
+func Test_stores_should_not_share_state(t *testing.T) {
+	store1 := NewResultInMemory()
+	store2 := NewResultInMemory()
+
+	id, err := store1.Save(context.Background(), &dao.Analyses{})
+	assert.NoError(t, err)
+
+	_, err = store2.Get(context.Background(), id)
+	assert.ErrorIs(t, err, ResultNotFoundErr)
+}
+
+func Test_save_should_evict_least_recently_used_item_when_full(t *testing.T) {
+	sut := newStoreWith(2, &dao.Analyses{}, &dao.Analyses{})
+
+	// touch the first item, making the second one the least recently used
+	_, err := sut.Get(context.Background(), 1)
+	assert.NoError(t, err)
+
+	id, err := sut.Save(context.Background(), &dao.Analyses{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), id)
+	assert.Len(t, sut.data, 2)
+	assert.Contains(t, sut.data, int64(1))
+	assert.Contains(t, sut.data, int64(3))
+	assert.NotContains(t, sut.data, int64(2))
+}
+
+func Test_save_should_not_evict_the_analyses_in_progress(t *testing.T) {
+	sut := newStoreWith(2,
+		&dao.Analyses{ProcessStatus: &dao.ProcessStatusRunning},
+		&dao.Analyses{ProcessStatus: &dao.ProcessStatusQueued})
+
+	id, err := sut.Save(context.Background(), &dao.Analyses{ProcessStatus: &dao.ProcessStatusQueued})
+
+	assert.NoError(t, err)
+	assert.Len(t, sut.data, 3)
+
+	// the store shrinks back to its capacity once the analyses finish
+	err = sut.Update(context.Background(), 1, &dao.Analyses{ProcessStatus: &dao.ProcessStatusCompleted})
+	assert.NoError(t, err)
+	err = sut.Update(context.Background(), id, &dao.Analyses{ProcessStatus: &dao.ProcessStatusCompleted})
+	assert.NoError(t, err)
+
+	assert.Len(t, sut.data, 2)
+	assert.NotContains(t, sut.data, int64(1))
+	assert.Contains(t, sut.data, int64(2))
+	assert.Contains(t, sut.data, id)
+}
+
+func Test_store_should_be_safe_for_concurrent_use(t *testing.T) {
+	sut := NewBoundedResultInMemory(10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := sut.Save(context.Background(), &dao.Analyses{})
+			assert.NoError(t, err)
+			_ = sut.Update(context.Background(), id, &dao.Analyses{Title: "updated"})
+			_, _ = sut.Get(context.Background(), id)
+			_, _ = sut.GetAll(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	results, err := sut.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, results, 10)
+}
+
+func Test_get_links_should_filter_and_page_the_links(t *testing.T) {
+	isInternal := true
+	notFound := 404
+	sut := newStoreWith(0, &dao.Analyses{Links: []*dao.Link{
+		{Url: "/a", IsInternal: true, LinkStatus: "Active", HttpStatusCode: 200},
+		{Url: "/b", IsInternal: true, LinkStatus: "Inactive", HttpStatusCode: 404},
+		{Url: "/c", IsInternal: true, LinkStatus: "Inactive", HttpStatusCode: 404},
+		{Url: "https://ext.com", LinkStatus: "Inactive", HttpStatusCode: 404},
+	}})
+
+	links, total, err := sut.GetLinks(context.Background(), 1, &dao.LinkQuery{
+		LinkStatus:     "Inactive",
+		IsInternal:     &isInternal,
+		HttpStatusCode: &notFound,
+		Offset:         1,
+		Limit:          1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "/c", links[0].Url)
+}
+
+func Test_get_links_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := newStoreWith(0, &dao.Analyses{})
+
+	links, _, err := sut.GetLinks(context.Background(), 2, &dao.LinkQuery{})
+
+	assert.ErrorIs(t, err, ResultNotFoundErr)
+	assert.Nil(t, links)
+}
+
+func newStoreWith(capacity int, items ...*dao.Analyses) *resultInMem {
+	sut := NewBoundedResultInMemory(capacity).(*resultInMem)
+	for _, item := range items {
+		_, _ = sut.Save(context.Background(), item)
 	}
 
+	return sut
+}
+
+func Test_query_should_filter_sort_and_page_the_items(t *testing.T) {
+	now := time.Now()
+	completed := now.Add(time.Minute)
+	hasLoginForm := true
+	sut := newStoreWith(0,
+		&dao.Analyses{Url: "https://www.Test.com/a", Requested: now, ProcessStatus: &dao.ProcessStatusCompleted,
+			Completed: &completed, HasLoginForm: true},
+		&dao.Analyses{Url: "https://www.test.com/b", Requested: now.Add(time.Second),
+			ProcessStatus: &dao.ProcessStatusCompleted, HasLoginForm: true},
+		&dao.Analyses{Url: "https://www.test.com/c", Requested: now.Add(2 * time.Second),
+			ProcessStatus: &dao.ProcessStatusCompleted, HasLoginForm: true},
+		&dao.Analyses{Url: "https://www.other.com", Requested: now, ProcessStatus: &dao.ProcessStatusCompleted,
+			HasLoginForm: true},
+		&dao.Analyses{Url: "https://www.test.com/d", Requested: now, ProcessStatus: &dao.ProcessStatusFailed},
+	)
+
+	results, total, err := sut.Query(context.Background(), &dao.AnalysesQuery{
+		ProcessStatus: string(dao.ProcessStatusCompleted),
+		UrlContains:   "TEST.com",
+		RequestedFrom: &now,
+		HasLoginForm:  &hasLoginForm,
+		SortBy:        dao.AnalysesSortRequested,
+		Descending:    true,
+		Offset:        1,
+		Limit:         1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "https://www.test.com/b", results[0].Url)
+
+	results, _, err = sut.Query(context.Background(), &dao.AnalysesQuery{
+		UrlContains: "test.com",
+		SortBy:      dao.AnalysesSortCompleted,
+		Descending:  true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.Test.com/a", results[0].Url)
+}
+
+func Test_query_should_return_an_empty_page_for_a_negative_offset(t *testing.T) {
+	sut := newStoreWith(0, &dao.Analyses{}, &dao.Analyses{})
+
+	results, total, err := sut.Query(context.Background(), &dao.AnalysesQuery{Offset: -1000, Limit: 500})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Empty(t, results)
 }
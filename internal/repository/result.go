@@ -11,5 +11,10 @@ type Results interface {
 	Remove(ctx context.Context, id int64) error
 	Get(ctx context.Context, id int64) (*dao.Analyses, error)
 	GetAll(ctx context.Context) ([]*dao.Analyses, error)
+	// Query returns a page of the analyses matching the query, and the total number of matches.
+	// The links of the analyses are not loaded, use GetLinks for them.
+	Query(ctx context.Context, q *dao.AnalysesQuery) ([]*dao.Analyses, int, error)
 	Update(context.Context, int64, *dao.Analyses) error
+	// GetLinks returns a page of the links of the analysis matching the query, and the total number of matches.
+	GetLinks(ctx context.Context, id int64, q *dao.LinkQuery) ([]*dao.Link, int, error)
 }
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+)
+
+type crawlSqlite struct {
+	db *sql.DB
+}
+
+func NewCrawlSqlite(db *sql.DB) repository.Crawls {
+	return &crawlSqlite{
+		db: db,
+	}
+}
+
+func (r *crawlSqlite) Save(ctx context.Context, m *dao.Crawl) (int64, error) {
+	pages, err := nullJson(m.Pages)
+	if err != nil {
+		return 0, fmt.Errorf("unable to encode the crawled pages, %v", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `INSERT INTO crawls (
+		url, requested, completed, process_status, max_depth, max_pages, pages
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		m.Url, m.Requested.UnixNano(), nullTime(m.Completed), nullStatus(m.ProcessStatus), m.MaxDepth, m.MaxPages, pages)
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert the crawl, %v", err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *crawlSqlite) Update(ctx context.Context, id int64, m *dao.Crawl) error {
+	pages, err := nullJson(m.Pages)
+	if err != nil {
+		return fmt.Errorf("unable to encode the crawled pages, %v", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, `UPDATE crawls SET
+		url = ?, requested = ?, completed = ?, process_status = ?, max_depth = ?, max_pages = ?, pages = ?
+	WHERE id = ?`,
+		m.Url, m.Requested.UnixNano(), nullTime(m.Completed), nullStatus(m.ProcessStatus), m.MaxDepth, m.MaxPages, pages,
+		id)
+	if err != nil {
+		return fmt.Errorf("unable to update the crawl %d, %v", id, err)
+	}
+
+	return mustAffectCrawl(res)
+}
+
+func (r *crawlSqlite) Remove(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM crawls WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to remove the crawl %d, %v", id, err)
+	}
+
+	return mustAffectCrawl(res)
+}
+
+func (r *crawlSqlite) Get(ctx context.Context, id int64) (*dao.Crawl, error) {
+	item := &dao.Crawl{}
+	var requested int64
+	var completed sql.NullInt64
+	var status sql.NullString
+	var pages sql.NullString
+
+	if err := r.db.QueryRowContext(ctx, `SELECT
+		id, url, requested, completed, process_status, max_depth, max_pages, pages
+	FROM crawls WHERE id = ?`, id).Scan(&item.Id, &item.Url, &requested, &completed, &status,
+		&item.MaxDepth, &item.MaxPages, &pages); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.CrawlNotFoundErr
+		}
+
+		return nil, err
+	}
+
+	item.Requested = time.Unix(0, requested)
+	if completed.Valid {
+		t := time.Unix(0, completed.Int64)
+		item.Completed = &t
+	}
+
+	if status.Valid {
+		ps := dao.ProcessStatus(status.String)
+		item.ProcessStatus = &ps
+	}
+
+	if err := unmarshalNullJson(pages, &item.Pages); err != nil {
+		return nil, fmt.Errorf("unable to decode the pages of crawl %d, %v", item.Id, err)
+	}
+
+	return item, nil
+}
+
+func mustAffectCrawl(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return repository.CrawlNotFoundErr
+	}
+
+	return nil
+}
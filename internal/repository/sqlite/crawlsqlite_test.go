@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_crawl_save_and_get_should_round_trip_the_pages(t *testing.T) {
+	sut := NewCrawlSqlite(openTestDb(t))
+	requested := time.Now()
+	completed := requested.Add(time.Minute)
+	item := &dao.Crawl{
+		Url:           "https://www.test.com",
+		Requested:     requested,
+		Completed:     &completed,
+		ProcessStatus: &dao.ProcessStatusCompleted,
+		MaxDepth:      2,
+		MaxPages:      10,
+		Pages: []*dao.CrawlPage{
+			{Url: "https://www.test.com", AnalysisId: 1, ProcessStatus: &dao.ProcessStatusCompleted, StatusCode: 200,
+				Title: "home", InternalLinkCount: 1},
+			{Url: "https://www.test.com/about", Depth: 1, AnalysisId: 2, InboundLinkCount: 1,
+				ProcessStatus: &dao.ProcessStatusFailed},
+			{Url: "https://www.test.com/old", AnalysisId: 3, Orphaned: true},
+		},
+	}
+
+	id, err := sut.Save(context.Background(), item)
+	assert.NoError(t, err)
+	assert.Greater(t, id, int64(0))
+
+	result, err := sut.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, result.Id)
+	assert.Equal(t, item.Url, result.Url)
+	assert.True(t, item.Requested.Equal(result.Requested))
+	assert.True(t, item.Completed.Equal(*result.Completed))
+	assert.Equal(t, dao.ProcessStatusCompleted, *result.ProcessStatus)
+	assert.Equal(t, item.MaxDepth, result.MaxDepth)
+	assert.Equal(t, item.MaxPages, result.MaxPages)
+	assert.Equal(t, item.Pages, result.Pages)
+}
+
+func Test_crawl_update_should_replace_the_values(t *testing.T) {
+	sut := NewCrawlSqlite(openTestDb(t))
+	id, err := sut.Save(context.Background(), &dao.Crawl{Url: "https://www.test.com", Requested: time.Now()})
+	assert.NoError(t, err)
+
+	err = sut.Update(context.Background(), id, &dao.Crawl{
+		Url:           "https://www.test.com",
+		Requested:     time.Now(),
+		ProcessStatus: &dao.ProcessStatusRunning,
+		Pages:         []*dao.CrawlPage{{Url: "https://www.test.com", AnalysisId: 1}},
+	})
+	assert.NoError(t, err)
+
+	result, err := sut.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, dao.ProcessStatusRunning, *result.ProcessStatus)
+	assert.Equal(t, []*dao.CrawlPage{{Url: "https://www.test.com", AnalysisId: 1}}, result.Pages)
+}
+
+func Test_crawl_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewCrawlSqlite(openTestDb(t))
+
+	_, err := sut.Get(context.Background(), 2)
+	assert.ErrorIs(t, err, repository.CrawlNotFoundErr)
+
+	err = sut.Update(context.Background(), 2, &dao.Crawl{})
+	assert.ErrorIs(t, err, repository.CrawlNotFoundErr)
+
+	err = sut.Remove(context.Background(), 2)
+	assert.ErrorIs(t, err, repository.CrawlNotFoundErr)
+}
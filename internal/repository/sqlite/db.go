@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	// registers the pure go "sqlite" driver, keeps the binary buildable with CGO_ENABLED=0
+	_ "modernc.org/sqlite"
+)
+
+// Open opens the sqlite database at the given path and brings the schema up to date
+// by running all the pending migrations.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open sqlite database %q, %v", path, err)
+	}
+
+	// sqlite allows only a single writer, serializing the connections avoid "database is locked" errors
+	db.SetMaxOpenConns(1)
+
+	if err := migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
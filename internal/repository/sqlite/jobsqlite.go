@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+)
+
+type jobSqlite struct {
+	db *sql.DB
+}
+
+func NewJobSqlite(db *sql.DB) repository.Jobs {
+	return &jobSqlite{
+		db: db,
+	}
+}
+
+func (r *jobSqlite) Save(ctx context.Context, j *dao.Job) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `INSERT INTO jobs (type, target_id, url, created) VALUES (?, ?, ?, ?)`,
+		string(j.Type), j.TargetId, j.Url, j.Created.UnixNano())
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert the job, %v", err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *jobSqlite) Remove(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM jobs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to remove the job %d, %v", id, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return repository.JobNotFoundErr
+	}
+
+	return nil
+}
+
+func (r *jobSqlite) GetAll(ctx context.Context) ([]*dao.Job, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, type, target_id, url, created FROM jobs ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query the jobs, %v", err)
+	}
+	defer rows.Close()
+
+	jobs := []*dao.Job{}
+	for rows.Next() {
+		j := &dao.Job{}
+		var typ string
+		var created int64
+		if err := rows.Scan(&j.Id, &typ, &j.TargetId, &j.Url, &created); err != nil {
+			return nil, err
+		}
+		j.Type = dao.JobType(typ)
+		j.Created = time.Unix(0, created)
+		jobs = append(jobs, j)
+	}
+
+	return jobs, rows.Err()
+}
@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_job_save_and_get_all_should_return_jobs_in_order(t *testing.T) {
+	sut := NewJobSqlite(openTestDb(t))
+	created := time.Now()
+
+	id1, err := sut.Save(context.Background(), &dao.Job{
+		Type: dao.JobTypeAnalysis, TargetId: 10, Url: "https://www.test.com", Created: created,
+	})
+	assert.NoError(t, err)
+	id2, err := sut.Save(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 11, Created: created})
+	assert.NoError(t, err)
+
+	jobs, err := sut.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+	assert.Equal(t, id1, jobs[0].Id)
+	assert.Equal(t, dao.JobTypeAnalysis, jobs[0].Type)
+	assert.Equal(t, int64(10), jobs[0].TargetId)
+	assert.Equal(t, "https://www.test.com", jobs[0].Url)
+	assert.True(t, created.Equal(jobs[0].Created))
+	assert.Equal(t, id2, jobs[1].Id)
+}
+
+func Test_job_remove_should_remove_the_job(t *testing.T) {
+	sut := NewJobSqlite(openTestDb(t))
+	id, err := sut.Save(context.Background(), &dao.Job{Created: time.Now()})
+	assert.NoError(t, err)
+
+	err = sut.Remove(context.Background(), id)
+	assert.NoError(t, err)
+
+	jobs, err := sut.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func Test_job_remove_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewJobSqlite(openTestDb(t))
+
+	err := sut.Remove(context.Background(), 2)
+
+	assert.ErrorIs(t, err, repository.JobNotFoundErr)
+}
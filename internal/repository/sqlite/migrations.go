@@ -0,0 +1,116 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrations are applied in order and only once, the version of a migration is its index + 1.
+// Never change an existing migration, append a new one instead.
+var migrations = []string{
+	`CREATE TABLE analyses (
+		id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+		url                 TEXT    NOT NULL,
+		requested           INTEGER NOT NULL,
+		completed           INTEGER NULL,
+		process_status      TEXT    NULL,
+		title               TEXT    NOT NULL DEFAULT '',
+		headings            TEXT    NULL,
+		internal_link_count INTEGER NOT NULL DEFAULT 0,
+		external_link_count INTEGER NOT NULL DEFAULT 0,
+		active_link_count   INTEGER NOT NULL DEFAULT 0,
+		inactive_link_count INTEGER NOT NULL DEFAULT 0,
+		page_version        TEXT    NOT NULL DEFAULT '',
+		has_login_form      INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE TABLE links (
+		analysis_id      INTEGER NOT NULL REFERENCES analyses (id) ON DELETE CASCADE,
+		position         INTEGER NOT NULL,
+		name             TEXT    NOT NULL DEFAULT '',
+		url              TEXT    NOT NULL DEFAULT '',
+		is_internal      INTEGER NOT NULL DEFAULT 0,
+		link_status      TEXT    NOT NULL DEFAULT '',
+		http_status_code INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (analysis_id, position)
+	);`,
+	`CREATE TABLE jobs (
+		id        INTEGER PRIMARY KEY AUTOINCREMENT,
+		type      TEXT    NOT NULL,
+		target_id INTEGER NOT NULL,
+		url       TEXT    NOT NULL DEFAULT '',
+		created   INTEGER NOT NULL
+	);`,
+	`CREATE INDEX links_analysis_status ON links (analysis_id, link_status, http_status_code);`,
+	`CREATE INDEX analyses_requested ON analyses (requested);
+	CREATE INDEX analyses_completed ON analyses (completed);`,
+	`ALTER TABLE analyses ADD COLUMN status_code INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE analyses ADD COLUMN headers TEXT NULL;`,
+	`ALTER TABLE analyses ADD COLUMN final_url TEXT NOT NULL DEFAULT '';
+	ALTER TABLE analyses ADD COLUMN redirects TEXT NULL;`,
+	`CREATE TABLE crawls (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		url            TEXT    NOT NULL,
+		requested      INTEGER NOT NULL,
+		completed      INTEGER NULL,
+		process_status TEXT    NULL,
+		max_depth      INTEGER NOT NULL DEFAULT 0,
+		max_pages      INTEGER NOT NULL DEFAULT 0,
+		pages          TEXT    NULL
+	);`,
+	`ALTER TABLE analyses ADD COLUMN blocked_link_count INTEGER NOT NULL DEFAULT 0;`,
+	`ALTER TABLE links ADD COLUMN resolved_url TEXT NOT NULL DEFAULT '';`,
+	`ALTER TABLE links ADD COLUMN link_type TEXT NOT NULL DEFAULT '';
+	ALTER TABLE analyses ADD COLUMN link_type_counts TEXT NULL;`,
+	`ALTER TABLE analyses ADD COLUMN sections TEXT NULL;`,
+}
+
+func migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		applied INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("unable to create schema_migrations table, %v", err)
+	}
+
+	current := 0
+	if err := db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("unable to read the current schema version, %v", err)
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+		logrus.Infof("Applying sqlite schema migration %d", version)
+		if err := applyMigration(ctx, db, version, migrations[i]); err != nil {
+			return fmt.Errorf("unable to apply schema migration %d, %v", version, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, version int, stmt string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, applied) VALUES (?, ?)`,
+		version, time.Now().UnixNano()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
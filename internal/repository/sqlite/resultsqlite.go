@@ -0,0 +1,493 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+)
+
+// analysesWriteColumns are the columns written by Save and Update, in the order of analysesValues.
+var analysesWriteColumns = []string{
+	"url", "requested", "completed", "process_status", "final_url", "redirects", "status_code", "headers",
+	"title", "headings",
+	"internal_link_count", "external_link_count", "active_link_count", "inactive_link_count",
+	"blocked_link_count", "link_type_counts",
+	"page_version", "has_login_form", "sections",
+}
+
+// analysesColumns are the columns read by scanAnalyses.
+var analysesColumns = "id, " + strings.Join(analysesWriteColumns, ", ")
+
+type resultSqlite struct {
+	db *sql.DB
+}
+
+func NewResultSqlite(db *sql.DB) repository.Results {
+	return &resultSqlite{
+		db: db,
+	}
+}
+
+func (r *resultSqlite) Save(ctx context.Context, m *dao.Analyses) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	values, err := analysesValues(m)
+	if err != nil {
+		return 0, err
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(analysesWriteColumns)), ", ")
+	res, err := tx.ExecContext(ctx, `INSERT INTO analyses (`+strings.Join(analysesWriteColumns, ", ")+
+		`) VALUES (`+placeholders+`)`, values...)
+	if err != nil {
+		return 0, fmt.Errorf("unable to insert the analysis, %v", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertLinks(ctx, tx, id, m.Links); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+func (r *resultSqlite) Update(ctx context.Context, id int64, m *dao.Analyses) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	values, err := analysesValues(m)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE analyses SET `+strings.Join(analysesWriteColumns, " = ?, ")+
+		` = ? WHERE id = ?`, append(values, id)...)
+	if err != nil {
+		return fmt.Errorf("unable to update the analysis %d, %v", id, err)
+	}
+
+	if err := mustAffectRow(res); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM links WHERE analysis_id = ?`, id); err != nil {
+		return fmt.Errorf("unable to clear the links of analysis %d, %v", id, err)
+	}
+
+	if err := insertLinks(ctx, tx, id, m.Links); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *resultSqlite) Remove(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM links WHERE analysis_id = ?`, id); err != nil {
+		return fmt.Errorf("unable to remove the links of analysis %d, %v", id, err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM analyses WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("unable to remove the analysis %d, %v", id, err)
+	}
+
+	if err := mustAffectRow(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *resultSqlite) Get(ctx context.Context, id int64) (*dao.Analyses, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+analysesColumns+` FROM analyses WHERE id = ?`, id)
+	item, err := scanAnalyses(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ResultNotFoundErr
+		}
+
+		return nil, err
+	}
+
+	links, err := r.links(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Links = links
+
+	return item, nil
+}
+
+func (r *resultSqlite) GetAll(ctx context.Context) ([]*dao.Analyses, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+analysesColumns+` FROM analyses ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query the analyses, %v", err)
+	}
+	defer rows.Close()
+
+	results := []*dao.Analyses{}
+	for rows.Next() {
+		item, err := scanAnalyses(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range results {
+		links, err := r.links(ctx, item.Id)
+		if err != nil {
+			return nil, err
+		}
+		item.Links = links
+	}
+
+	return results, nil
+}
+
+func (r *resultSqlite) Query(ctx context.Context, q *dao.AnalysesQuery) ([]*dao.Analyses, int, error) {
+	where := `1 = 1`
+	args := []any{}
+	if q.ProcessStatus != "" {
+		where += ` AND process_status = ?`
+		args = append(args, q.ProcessStatus)
+	}
+
+	if q.UrlContains != "" {
+		where += ` AND instr(lower(url), lower(?)) > 0`
+		args = append(args, q.UrlContains)
+	}
+
+	if q.RequestedFrom != nil {
+		where += ` AND requested >= ?`
+		args = append(args, q.RequestedFrom.UnixNano())
+	}
+
+	if q.RequestedTo != nil {
+		where += ` AND requested <= ?`
+		args = append(args, q.RequestedTo.UnixNano())
+	}
+
+	if q.HasLoginForm != nil {
+		where += ` AND has_login_form = ?`
+		args = append(args, *q.HasLoginForm)
+	}
+
+	total := 0
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM analyses WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("unable to count the analyses, %v", err)
+	}
+
+	direction := `ASC`
+	if q.Descending {
+		direction = `DESC`
+	}
+
+	orderBy := `requested ` + direction + `, id ` + direction
+	if q.SortBy == dao.AnalysesSortCompleted {
+		orderBy = `completed IS NULL, completed ` + direction + `, id ` + direction
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+analysesColumns+` FROM analyses WHERE `+where+
+		` ORDER BY `+orderBy+` LIMIT ? OFFSET ?`, append(args, limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to query the analyses, %v", err)
+	}
+	defer rows.Close()
+
+	results := []*dao.Analyses{}
+	for rows.Next() {
+		item, err := scanAnalyses(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		results = append(results, item)
+	}
+
+	return results, total, rows.Err()
+}
+
+func (r *resultSqlite) GetLinks(ctx context.Context, id int64, q *dao.LinkQuery) ([]*dao.Link, int, error) {
+	exists := false
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM analyses WHERE id = ?)`, id).Scan(&exists); err != nil {
+		return nil, 0, fmt.Errorf("unable to query the analysis %d, %v", id, err)
+	}
+
+	if !exists {
+		return nil, 0, repository.ResultNotFoundErr
+	}
+
+	where := `analysis_id = ?`
+	args := []any{id}
+	if q.LinkStatus != "" {
+		where += ` AND link_status = ?`
+		args = append(args, q.LinkStatus)
+	}
+
+	if q.IsInternal != nil {
+		where += ` AND is_internal = ?`
+		args = append(args, *q.IsInternal)
+	}
+
+	if q.HttpStatusCode != nil {
+		where += ` AND http_status_code = ?`
+		args = append(args, *q.HttpStatusCode)
+	}
+
+	total := 0
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM links WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("unable to count the links of analysis %d, %v", id, err)
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	links, err := r.queryLinks(ctx, `SELECT name, url, resolved_url, link_type, is_internal, link_status, http_status_code
+		FROM links WHERE `+where+` ORDER BY position LIMIT ? OFFSET ?`, append(args, limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to query the links of analysis %d, %v", id, err)
+	}
+
+	return links, total, nil
+}
+
+func (r *resultSqlite) links(ctx context.Context, id int64) ([]*dao.Link, error) {
+	links, err := r.queryLinks(ctx, `SELECT name, url, resolved_url, link_type, is_internal, link_status, http_status_code
+		FROM links WHERE analysis_id = ? ORDER BY position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query the links of analysis %d, %v", id, err)
+	}
+
+	return links, nil
+}
+
+func (r *resultSqlite) queryLinks(ctx context.Context, query string, args ...any) ([]*dao.Link, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []*dao.Link{}
+	for rows.Next() {
+		l := &dao.Link{}
+		if err := rows.Scan(
+			&l.Name, &l.Url, &l.ResolvedUrl, &l.Type, &l.IsInternal, &l.LinkStatus, &l.HttpStatusCode,
+		); err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+func insertLinks(ctx context.Context, tx *sql.Tx, id int64, links []*dao.Link) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO links (
+		analysis_id, position, name, url, resolved_url, link_type, is_internal, link_status, http_status_code
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i, l := range links {
+		if _, err := stmt.ExecContext(ctx,
+			id, i, l.Name, l.Url, l.ResolvedUrl, l.Type, l.IsInternal, l.LinkStatus, l.HttpStatusCode); err != nil {
+			return fmt.Errorf("unable to insert the links of analysis %d, %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func analysesValues(m *dao.Analyses) ([]any, error) {
+	redirects, err := nullJson(m.Redirects)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the redirects, %v", err)
+	}
+
+	headers, err := nullJson(m.Headers)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the headers, %v", err)
+	}
+
+	headings, err := nullJson(m.Headings)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the headings, %v", err)
+	}
+
+	linkTypeCounts, err := nullJson(m.LinkTypeCounts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the link type counts, %v", err)
+	}
+
+	sections, err := nullJson(m.Sections)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode the sections, %v", err)
+	}
+
+	return []any{
+		m.Url, m.Requested.UnixNano(), nullTime(m.Completed), nullStatus(m.ProcessStatus), m.FinalUrl, redirects,
+		m.StatusCode, headers, m.Title, headings,
+		m.InternalLinkCount, m.ExternalLinkCount, m.ActiveLinkCount, m.InactiveLinkCount, m.BlockedLinkCount,
+		linkTypeCounts, m.PageVersion, m.HasLoginForm, sections,
+	}, nil
+}
+
+func scanAnalyses(s scanner) (*dao.Analyses, error) {
+	item := &dao.Analyses{}
+	var requested int64
+	var completed sql.NullInt64
+	var status sql.NullString
+	var redirects sql.NullString
+	var headers sql.NullString
+	var headings sql.NullString
+	var linkTypeCounts sql.NullString
+	var sections sql.NullString
+
+	if err := s.Scan(&item.Id, &item.Url, &requested, &completed, &status, &item.FinalUrl, &redirects,
+		&item.StatusCode, &headers, &item.Title, &headings,
+		&item.InternalLinkCount, &item.ExternalLinkCount, &item.ActiveLinkCount, &item.InactiveLinkCount,
+		&item.BlockedLinkCount, &linkTypeCounts, &item.PageVersion, &item.HasLoginForm, &sections); err != nil {
+		return nil, err
+	}
+
+	item.Requested = time.Unix(0, requested)
+	if completed.Valid {
+		t := time.Unix(0, completed.Int64)
+		item.Completed = &t
+	}
+
+	if status.Valid {
+		ps := dao.ProcessStatus(status.String)
+		item.ProcessStatus = &ps
+	}
+
+	if err := unmarshalNullJson(redirects, &item.Redirects); err != nil {
+		return nil, fmt.Errorf("unable to decode the redirects of analysis %d, %v", item.Id, err)
+	}
+
+	if err := unmarshalNullJson(headers, &item.Headers); err != nil {
+		return nil, fmt.Errorf("unable to decode the headers of analysis %d, %v", item.Id, err)
+	}
+
+	if err := unmarshalNullJson(headings, &item.Headings); err != nil {
+		return nil, fmt.Errorf("unable to decode the headings of analysis %d, %v", item.Id, err)
+	}
+
+	if err := unmarshalNullJson(linkTypeCounts, &item.LinkTypeCounts); err != nil {
+		return nil, fmt.Errorf("unable to decode the link type counts of analysis %d, %v", item.Id, err)
+	}
+
+	if err := unmarshalNullJson(sections, &item.Sections); err != nil {
+		return nil, fmt.Errorf("unable to decode the sections of analysis %d, %v", item.Id, err)
+	}
+
+	return item, nil
+}
+
+func mustAffectRow(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return repository.ResultNotFoundErr
+	}
+
+	return nil
+}
+
+// nullJson encodes v as a json column, nil maps, slices and pointers are stored as NULL.
+func nullJson(v any) (sql.NullString, error) {
+	if v == nil {
+		return sql.NullString{}, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	if string(b) == "null" {
+		return sql.NullString{}, nil
+	}
+
+	return sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func unmarshalNullJson(ns sql.NullString, v any) error {
+	if !ns.Valid {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(ns.String), v)
+}
+
+func nullTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func nullStatus(ps *dao.ProcessStatus) sql.NullString {
+	if ps == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(*ps), Valid: true}
+}
@@ -0,0 +1,265 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestDb(t *testing.T) *sql.DB {
+	db, err := Open(context.Background(), ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func Test_open_should_apply_all_migrations(t *testing.T) {
+	db := openTestDb(t)
+
+	version := 0
+	err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+}
+
+func Test_migrate_should_be_idempotent(t *testing.T) {
+	db := openTestDb(t)
+
+	err := migrate(context.Background(), db)
+
+	assert.NoError(t, err)
+}
+
+func Test_save_and_get_should_round_trip_analysis_with_links(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+	requested := time.Now()
+	completed := requested.Add(time.Second)
+	item := &dao.Analyses{
+		Url:               "https://www.test.com",
+		Requested:         requested,
+		Completed:         &completed,
+		ProcessStatus:     &dao.ProcessStatusCompleted,
+		FinalUrl:          "https://www.test.com/en/",
+		Redirects:         []*dao.Redirect{{Url: "https://www.test.com", StatusCode: 301, Location: "/en/"}},
+		StatusCode:        404,
+		Headers:           map[string][]string{"Content-Type": {"text/html"}},
+		Title:             "test",
+		Headings:          map[string]int{"h1": 1, "h2": 3},
+		InternalLinkCount: 1,
+		ExternalLinkCount: 1,
+		ActiveLinkCount:   1,
+		InactiveLinkCount: 1,
+		BlockedLinkCount:  1,
+		LinkTypeCounts:    map[string]int{"http": 2},
+		Sections:          map[string]json.RawMessage{"seo": json.RawMessage(`{"score":80}`)},
+		PageVersion:       "HTML5 and beyond",
+		HasLoginForm:      true,
+		Links: []*dao.Link{
+			{Name: "home", Url: "/", ResolvedUrl: "https://www.test.com/", Type: "http", IsInternal: true,
+				LinkStatus: "Active", HttpStatusCode: 200},
+			{Name: "ext", Url: "https://ext.com", Type: "http", LinkStatus: "Inactive", HttpStatusCode: 404},
+		},
+	}
+
+	id, err := sut.Save(context.Background(), item)
+	assert.NoError(t, err)
+	assert.Greater(t, id, int64(0))
+
+	result, err := sut.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, result.Id)
+	assert.Equal(t, item.Url, result.Url)
+	assert.True(t, item.Requested.Equal(result.Requested))
+	assert.True(t, item.Completed.Equal(*result.Completed))
+	assert.Equal(t, dao.ProcessStatusCompleted, *result.ProcessStatus)
+	assert.Equal(t, item.FinalUrl, result.FinalUrl)
+	assert.Equal(t, item.Redirects, result.Redirects)
+	assert.Equal(t, item.StatusCode, result.StatusCode)
+	assert.Equal(t, item.Headers, result.Headers)
+	assert.Equal(t, item.Title, result.Title)
+	assert.Equal(t, item.Headings, result.Headings)
+	assert.Equal(t, item.BlockedLinkCount, result.BlockedLinkCount)
+	assert.Equal(t, item.LinkTypeCounts, result.LinkTypeCounts)
+	assert.Equal(t, item.Sections, result.Sections)
+	assert.Equal(t, item.PageVersion, result.PageVersion)
+	assert.True(t, result.HasLoginForm)
+	assert.Equal(t, item.Links, result.Links)
+}
+
+func Test_update_should_replace_the_values_and_links(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+	id, err := sut.Save(context.Background(), &dao.Analyses{
+		Url:           "https://www.test.com",
+		Requested:     time.Now(),
+		ProcessStatus: &dao.ProcessStatusCreated,
+		Links:         []*dao.Link{{Url: "/old"}},
+	})
+	assert.NoError(t, err)
+
+	err = sut.Update(context.Background(), id, &dao.Analyses{
+		Url:           "https://www.test-updated.com",
+		Requested:     time.Now(),
+		ProcessStatus: &dao.ProcessStatusCompleted,
+		Title:         "test updated",
+		Links:         []*dao.Link{{Url: "/new"}},
+	})
+	assert.NoError(t, err)
+
+	result, err := sut.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.test-updated.com", result.Url)
+	assert.Equal(t, "test updated", result.Title)
+	assert.Equal(t, dao.ProcessStatusCompleted, *result.ProcessStatus)
+	assert.Equal(t, []*dao.Link{{Url: "/new"}}, result.Links)
+}
+
+func Test_update_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+
+	err := sut.Update(context.Background(), 2, &dao.Analyses{})
+
+	assert.ErrorIs(t, err, repository.ResultNotFoundErr)
+}
+
+func Test_remove_should_remove_the_item_and_its_links(t *testing.T) {
+	db := openTestDb(t)
+	sut := NewResultSqlite(db)
+	id, err := sut.Save(context.Background(), &dao.Analyses{Links: []*dao.Link{{Url: "/"}}})
+	assert.NoError(t, err)
+
+	err = sut.Remove(context.Background(), id)
+	assert.NoError(t, err)
+
+	_, err = sut.Get(context.Background(), id)
+	assert.ErrorIs(t, err, repository.ResultNotFoundErr)
+
+	links := 0
+	assert.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM links`).Scan(&links))
+	assert.Zero(t, links)
+}
+
+func Test_remove_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+
+	err := sut.Remove(context.Background(), 2)
+
+	assert.ErrorIs(t, err, repository.ResultNotFoundErr)
+}
+
+func Test_get_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+
+	result, err := sut.Get(context.Background(), 2)
+
+	assert.ErrorIs(t, err, repository.ResultNotFoundErr)
+	assert.Nil(t, result)
+}
+
+func Test_get_all_return_all_the_items(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+	for i := 0; i < 3; i++ {
+		_, err := sut.Save(context.Background(), &dao.Analyses{Requested: time.Now()})
+		assert.NoError(t, err)
+	}
+
+	results, err := sut.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+}
+
+func Test_get_links_should_filter_and_page_the_links(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+	id, err := sut.Save(context.Background(), &dao.Analyses{Links: []*dao.Link{
+		{Url: "/a", IsInternal: true, LinkStatus: "Active", HttpStatusCode: 200},
+		{Url: "/b", IsInternal: true, LinkStatus: "Inactive", HttpStatusCode: 404},
+		{Url: "/c", IsInternal: true, LinkStatus: "Inactive", HttpStatusCode: 404},
+		{Url: "https://ext.com", LinkStatus: "Inactive", HttpStatusCode: 404},
+	}})
+	assert.NoError(t, err)
+	isInternal := true
+	notFound := 404
+
+	links, total, err := sut.GetLinks(context.Background(), id, &dao.LinkQuery{
+		LinkStatus:     "Inactive",
+		IsInternal:     &isInternal,
+		HttpStatusCode: &notFound,
+		Offset:         1,
+		Limit:          1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, links, 1)
+	assert.Equal(t, "/c", links[0].Url)
+
+	links, total, err = sut.GetLinks(context.Background(), id, &dao.LinkQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Len(t, links, 4)
+}
+
+func Test_get_links_should_throw_an_error_for_invalid_id(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+
+	links, _, err := sut.GetLinks(context.Background(), 2, &dao.LinkQuery{})
+
+	assert.ErrorIs(t, err, repository.ResultNotFoundErr)
+	assert.Nil(t, links)
+}
+
+func Test_query_should_filter_sort_and_page_the_items(t *testing.T) {
+	sut := NewResultSqlite(openTestDb(t))
+	now := time.Now()
+	completed := now.Add(time.Minute)
+	hasLoginForm := true
+	for _, item := range []*dao.Analyses{
+		{Url: "https://www.Test.com/a", Requested: now, ProcessStatus: &dao.ProcessStatusCompleted,
+			Completed: &completed, HasLoginForm: true},
+		{Url: "https://www.test.com/b", Requested: now.Add(time.Second),
+			ProcessStatus: &dao.ProcessStatusCompleted, HasLoginForm: true},
+		{Url: "https://www.test.com/c", Requested: now.Add(2 * time.Second),
+			ProcessStatus: &dao.ProcessStatusCompleted, HasLoginForm: true},
+		{Url: "https://www.other.com", Requested: now, ProcessStatus: &dao.ProcessStatusCompleted,
+			HasLoginForm: true},
+		{Url: "https://www.test.com/d", Requested: now, ProcessStatus: &dao.ProcessStatusFailed},
+	} {
+		_, err := sut.Save(context.Background(), item)
+		assert.NoError(t, err)
+	}
+
+	results, total, err := sut.Query(context.Background(), &dao.AnalysesQuery{
+		ProcessStatus: string(dao.ProcessStatusCompleted),
+		UrlContains:   "TEST.com",
+		RequestedFrom: &now,
+		HasLoginForm:  &hasLoginForm,
+		SortBy:        dao.AnalysesSortRequested,
+		Descending:    true,
+		Offset:        1,
+		Limit:         1,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "https://www.test.com/b", results[0].Url)
+
+	results, _, err = sut.Query(context.Background(), &dao.AnalysesQuery{
+		UrlContains: "test.com",
+		SortBy:      dao.AnalysesSortCompleted,
+		Descending:  true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.Test.com/a", results[0].Url)
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/dto"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+	"github.com/DiLRandI/web-analyser/internal/service/job"
+	"github.com/DiLRandI/web-analyser/internal/service/webpage"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCrawlDepth = 2
+	defaultCrawlPages = 50
+	maxCrawlPages     = 500
+)
+
+func (s *processor) ProcessSite(ctx context.Context, req *dto.CrawlRequest) (*dto.AnalysesResponse, error) {
+	if req.WebUrl == "" {
+		return nil, &ValidationError{msg: "WebUrl is required."}
+	}
+
+	if _, ok := normalizeUrl(req.WebUrl); !ok {
+		return nil, &ValidationError{msg: fmt.Sprintf("WebUrl %q is not a valid http(s) url", req.WebUrl)}
+	}
+
+	if req.MaxDepth < 0 || req.MaxPages < 0 || req.MaxPages > maxCrawlPages {
+		return nil, &ValidationError{
+			msg: fmt.Sprintf("maxDepth must be positive and maxPages between 1 and %d", maxCrawlPages),
+		}
+	}
+
+	crawl := &dao.Crawl{
+		Url:           req.WebUrl,
+		Requested:     time.Now(),
+		ProcessStatus: &dao.ProcessStatusQueued,
+		MaxDepth:      req.MaxDepth,
+		MaxPages:      req.MaxPages,
+	}
+	if crawl.MaxDepth == 0 {
+		crawl.MaxDepth = defaultCrawlDepth
+	}
+	if crawl.MaxPages == 0 {
+		crawl.MaxPages = defaultCrawlPages
+	}
+
+	id, err := s.crawls.Save(ctx, crawl)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.queue.Push(ctx, &dao.Job{
+		Type:     dao.JobTypeCrawl,
+		TargetId: id,
+		Url:      req.WebUrl,
+		Created:  crawl.Requested,
+	}); err != nil {
+		s.updateCrawlStatus(ctx, id, crawl, dao.ProcessStatusFailed)
+		return nil, err
+	}
+
+	return &dto.AnalysesResponse{Id: id}, nil
+}
+
+func (s *processor) GetSiteResultFor(ctx context.Context, id int64) (*dto.CrawlResponse, error) {
+	crawl, err := s.crawls.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.CrawlNotFoundErr) {
+			return nil, &NotFoundError{msg: err.Error()}
+		}
+
+		return nil, err
+	}
+
+	return toCrawlResponse(crawl), nil
+}
+
+// siteCrawl is the state of a running crawl, dao.Crawl.Pages doubles as the breadth first queue.
+type siteCrawl struct {
+	*dao.Crawl
+	// host is the host of the start page after the redirects, only its pages are crawled.
+	host string
+	// previous are the pages of an interrupted run, their analyses are reused.
+	previous map[string]*dao.CrawlPage
+	found    map[string]*dao.CrawlPage
+}
+
+func (s *processor) crawl(ctx context.Context, id int64) error {
+	crawl, err := s.crawls.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if isFinished(crawl.ProcessStatus) {
+		logrus.Infof("Skipping crawl %d, it is already %s", id, *crawl.ProcessStatus)
+		return nil
+	}
+
+	logrus.Infof("Starting crawl for url %q", crawl.Url)
+	s.updateCrawlStatus(ctx, id, crawl, dao.ProcessStatusRunning)
+
+	start, _ := normalizeUrl(crawl.Url)
+	c := &siteCrawl{
+		Crawl:    crawl,
+		host:     hostOf(start),
+		previous: map[string]*dao.CrawlPage{},
+		found:    map[string]*dao.CrawlPage{},
+	}
+	for _, p := range crawl.Pages {
+		c.previous[p.Url] = p
+	}
+	crawl.Pages = []*dao.CrawlPage{}
+	c.add(start, 0)
+
+	for i := 0; i < len(crawl.Pages); i++ {
+		if err := s.visit(ctx, c, crawl.Pages[i]); err != nil {
+			s.abortCrawl(ctx, id, crawl)
+			return err
+		}
+	}
+
+	for _, p := range s.orphanedPages(ctx, c) {
+		if err := s.visit(ctx, c, p); err != nil {
+			s.abortCrawl(ctx, id, crawl)
+			return err
+		}
+	}
+
+	// a cancelled sitemap download looks like a site without a sitemap
+	if ctx.Err() != nil {
+		s.abortCrawl(ctx, id, crawl)
+		return ctx.Err()
+	}
+
+	logrus.Infof("Crawl completed for url %q, %d pages found", crawl.Url, len(crawl.Pages))
+	crawl.Completed = timePtr(time.Now())
+	crawl.ProcessStatus = &dao.ProcessStatusCompleted
+	if err := s.crawls.Update(ctx, id, crawl); err != nil {
+		return fmt.Errorf("unable to update the crawl, %v", err)
+	}
+
+	return nil
+}
+
+// visit analyses the page and queues the internal pages it links to, the crawl is saved afterwards
+// so the report shows the progress and an interrupted crawl can be resumed.
+func (s *processor) visit(ctx context.Context, c *siteCrawl, p *dao.CrawlPage) error {
+	if p.AnalysisId == 0 {
+		id, err := s.result.Save(ctx, &dao.Analyses{
+			Url:           p.Url,
+			Requested:     time.Now(),
+			ProcessStatus: &dao.ProcessStatusQueued,
+		})
+		if err != nil {
+			return err
+		}
+		p.AnalysisId = id
+	}
+
+	if err := s.analyse(ctx, p.AnalysisId); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logrus.Warnf("Crawl %d unable to analyse %q, %v", c.Id, p.Url, err)
+	}
+
+	analysis, err := s.result.Get(ctx, p.AnalysisId)
+	switch {
+	case errors.Is(err, repository.ResultNotFoundErr):
+		// the analysis was removed while the crawl was running
+		p.ProcessStatus = &dao.ProcessStatusFailed
+	case err != nil:
+		return err
+	default:
+		summarisePage(p, analysis)
+		s.followLinks(c, p, analysis)
+	}
+
+	if err := s.crawls.Update(ctx, c.Id, c.Crawl); err != nil {
+		return fmt.Errorf("unable to update the crawl, %v", err)
+	}
+
+	return nil
+}
+
+func (s *processor) followLinks(c *siteCrawl, p *dao.CrawlPage, analysis *dao.Analyses) {
+	base := analysis.FinalUrl
+	if base == "" {
+		base = analysis.Url
+	}
+
+	// redirects of the start page, http to https or to the www host, move the whole site
+	if p.Depth == 0 && !p.Orphaned {
+		c.host = hostOf(base)
+	}
+
+	// links to the url the page redirects to are links to the page itself
+	if u, ok := normalizeUrl(base); ok && c.found[u] == nil {
+		c.found[u] = p
+	}
+
+	if p.Orphaned {
+		return
+	}
+
+	linked := map[string]bool{}
+	for _, l := range analysis.Links {
+		if !l.IsInternal {
+			continue
+		}
+
+		u, ok := normalizeUrl(l.ResolvedUrl)
+		if !ok || hostOf(u) != c.host || linked[u] {
+			continue
+		}
+		linked[u] = true
+
+		target := c.found[u]
+		if target == p {
+			continue
+		}
+
+		if target == nil && p.Depth < c.MaxDepth {
+			target = c.add(u, p.Depth+1)
+		}
+
+		if target != nil {
+			target.InboundLinkCount++
+		}
+	}
+}
+
+// orphanedPages adds the pages of the sitemap which were not reached through the links, as long as
+// the crawl has room for them.
+func (s *processor) orphanedPages(ctx context.Context, c *siteCrawl) []*dao.CrawlPage {
+	sitemapUrl := fmt.Sprintf("%s/sitemap.xml", c.host)
+	m, err := s.downloader.Download(ctx, sitemapUrl)
+	if err != nil || m.StatusCode != http.StatusOK {
+		logrus.Infof("Crawl %d has no sitemap at %q", c.Id, sitemapUrl)
+		return nil
+	}
+
+	urls, err := webpage.ParseSitemap(m.Content)
+	if err != nil {
+		logrus.Warnf("Crawl %d ignoring the sitemap at %q, %v", c.Id, sitemapUrl, err)
+		return nil
+	}
+
+	pages := []*dao.CrawlPage{}
+	for _, raw := range urls {
+		u, ok := normalizeUrl(raw)
+		if !ok || hostOf(u) != c.host || c.found[u] != nil {
+			continue
+		}
+
+		if p := c.add(u, 0); p != nil {
+			p.Orphaned = true
+			pages = append(pages, p)
+		}
+	}
+
+	return pages
+}
+
+// add appends a new page to the crawl, it returns nil when the crawl reached its page limit.
+func (c *siteCrawl) add(u string, depth int) *dao.CrawlPage {
+	if len(c.Pages) >= c.MaxPages {
+		return nil
+	}
+
+	p := &dao.CrawlPage{Url: u, Depth: depth}
+	if prev, ok := c.previous[u]; ok {
+		p.AnalysisId = prev.AnalysisId
+	}
+
+	c.Pages = append(c.Pages, p)
+	c.found[u] = p
+	return p
+}
+
+// abortCrawl works like abort, the analysis of the interrupted page is handled by analyse itself.
+func (s *processor) abortCrawl(ctx context.Context, id int64, m *dao.Crawl) {
+	switch {
+	case job.Cancelled(ctx):
+		s.updateCrawlStatus(context.Background(), id, m, dao.ProcessStatusCancelled)
+	case ctx.Err() != nil:
+		s.updateCrawlStatus(context.Background(), id, m, dao.ProcessStatusQueued)
+	default:
+		s.updateCrawlStatus(ctx, id, m, dao.ProcessStatusFailed)
+	}
+}
+
+func (s *processor) updateCrawlStatus(
+	ctx context.Context, id int64, m *dao.Crawl, ps dao.ProcessStatus,
+) {
+	m.ProcessStatus = &ps
+	if err := s.crawls.Update(ctx, id, m); err != nil {
+		logrus.Errorf("updating process status to %q failed for crawl id %d", ps, id)
+	}
+}
+
+func summarisePage(p *dao.CrawlPage, analysis *dao.Analyses) {
+	p.ProcessStatus = analysis.ProcessStatus
+	p.StatusCode = analysis.StatusCode
+	p.Title = analysis.Title
+	p.InternalLinkCount = analysis.InternalLinkCount
+	p.ExternalLinkCount = analysis.ExternalLinkCount
+	p.ActiveLinkCount = analysis.ActiveLinkCount
+	p.InactiveLinkCount = analysis.InactiveLinkCount
+	p.HasLoginForm = analysis.HasLoginForm
+}
+
+// normalizeUrl drops the fragment of an absolute http(s) url, so the same page is crawled only once.
+func normalizeUrl(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String(), true
+}
+
+// hostOf returns the scheme and host of the url.
+func hostOf(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+func toCrawlResponse(c *dao.Crawl) *dto.CrawlResponse {
+	m := &dto.CrawlResponse{
+		Id:               c.Id,
+		Url:              c.Url,
+		Requested:        c.Requested,
+		Completed:        c.Completed,
+		ProcessStatus:    string(*c.ProcessStatus),
+		MaxDepth:         c.MaxDepth,
+		MaxPages:         c.MaxPages,
+		PageCount:        len(c.Pages),
+		OrphanedPages:    []string{},
+		UnreachablePages: []string{},
+		Pages:            []*dto.CrawlPageResponse{},
+	}
+
+	for _, p := range c.Pages {
+		status := ""
+		if p.ProcessStatus != nil {
+			status = string(*p.ProcessStatus)
+		}
+
+		analysed := status == string(dao.ProcessStatusCompleted)
+		reachable := analysed && p.StatusCode < http.StatusBadRequest
+		if analysed {
+			m.AnalysedPageCount++
+			m.InternalLinkCount += p.InternalLinkCount
+			m.ExternalLinkCount += p.ExternalLinkCount
+			m.ActiveLinkCount += p.ActiveLinkCount
+			m.InactiveLinkCount += p.InactiveLinkCount
+			if p.HasLoginForm {
+				m.LoginFormPageCount++
+			}
+		}
+
+		if p.Orphaned {
+			m.OrphanedPages = append(m.OrphanedPages, p.Url)
+		}
+
+		if status == string(dao.ProcessStatusFailed) || (analysed && !reachable) {
+			m.UnreachablePages = append(m.UnreachablePages, p.Url)
+		}
+
+		m.Pages = append(m.Pages, &dto.CrawlPageResponse{
+			Url:               p.Url,
+			Depth:             p.Depth,
+			AnalysisId:        p.AnalysisId,
+			InboundLinkCount:  p.InboundLinkCount,
+			Orphaned:          p.Orphaned,
+			Reachable:         reachable,
+			ProcessStatus:     status,
+			StatusCode:        p.StatusCode,
+			Title:             p.Title,
+			InternalLinkCount: p.InternalLinkCount,
+			ExternalLinkCount: p.ExternalLinkCount,
+			ActiveLinkCount:   p.ActiveLinkCount,
+			InactiveLinkCount: p.InactiveLinkCount,
+			HasLoginForm:      p.HasLoginForm,
+		})
+	}
+
+	return m
+}
@@ -7,3 +7,19 @@ type NotFoundError struct {
 func (e *NotFoundError) Error() string {
 	return e.msg
 }
+
+type ConflictError struct {
+	msg string
+}
+
+func (e *ConflictError) Error() string {
+	return e.msg
+}
+
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
@@ -0,0 +1,280 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	QueueStoppedErr = errors.New("job queue is stopped")
+	JobPanickedErr  = errors.New("job panicked")
+)
+
+// Handler runs a single job, the ctx is cancelled when the queue is stopping or the job is cancelled,
+// use Cancelled to tell them apart.
+type Handler func(ctx context.Context, j *dao.Job) error
+
+// Queue is a FIFO queue of jobs consumed by a fixed size pool of workers.
+// Pending jobs are kept in a repository.Jobs, with a persistent store they survive a restart.
+type Queue interface {
+	// Start re-queues the pending jobs found in the store and starts the workers, a stopped queue
+	// can be started again.
+	Start(ctx context.Context, handler Handler) error
+	// Push persists the job and queues it for the workers.
+	Push(ctx context.Context, j *dao.Job) (int64, error)
+	// Cancel removes the pending job of the given target from the queue, or cancels its context
+	// if it is already running. It returns false when there is no such job.
+	Cancel(ctx context.Context, typ dao.JobType, targetId int64) (bool, error)
+	// Stop stops the workers and waits for them to return, the running jobs are interrupted
+	// and left in the store so they are picked up on the next Start.
+	Stop()
+}
+
+// Recover wraps the handler so a panicking job calls onPanic and fails with JobPanickedErr, onPanic gives
+// the target of the job a final status. Without it the queue only logs the panic and drops the job.
+func Recover(handler Handler, onPanic func(ctx context.Context, j *dao.Job)) Handler {
+	return func(ctx context.Context, j *dao.Job) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				// the ctx of the job may be cancelled already, the target must still be updated
+				onPanic(context.Background(), j)
+				err = fmt.Errorf("%w, %v", JobPanickedErr, p)
+			}
+		}()
+
+		return handler(ctx, j)
+	}
+}
+
+type cancelledKey struct{}
+
+// Cancelled reports whether the ctx given to a Handler was cancelled through Queue.Cancel,
+// as opposed to the queue stopping.
+func Cancelled(ctx context.Context) bool {
+	flag, ok := ctx.Value(cancelledKey{}).(*int32)
+	return ok && atomic.LoadInt32(flag) == 1
+}
+
+type runningJob struct {
+	job       *dao.Job
+	ctx       context.Context
+	cancel    context.CancelFunc
+	cancelled *int32
+}
+
+type queue struct {
+	store   repository.Jobs
+	workers int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending []*dao.Job
+	running map[int64]*runningJob
+	stopped bool
+
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func NewQueue(store repository.Jobs, workers int) Queue {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	q := &queue{
+		store:   store,
+		workers: workers,
+		running: make(map[int64]*runningJob),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	return q
+}
+
+func (q *queue) Start(ctx context.Context, handler Handler) error {
+	jobs, err := q.store.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to load the pending jobs, %v", err)
+	}
+
+	if len(jobs) > 0 {
+		logrus.Infof("Re-queueing %d pending jobs", len(jobs))
+	}
+
+	// the jobs pushed before the start are in the store too
+	q.mu.Lock()
+	queued := make(map[int64]bool, len(jobs))
+	for _, j := range jobs {
+		queued[j.Id] = true
+	}
+	for _, j := range q.pending {
+		if !queued[j.Id] {
+			jobs = append(jobs, j)
+		}
+	}
+	q.pending = jobs
+	q.stopped = false
+	q.mu.Unlock()
+
+	ctx, q.cancel = context.WithCancel(ctx)
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx, handler)
+	}
+
+	return nil
+}
+
+func (q *queue) Push(ctx context.Context, j *dao.Job) (int64, error) {
+	q.mu.Lock()
+	stopped := q.stopped
+	q.mu.Unlock()
+	if stopped {
+		return 0, QueueStoppedErr
+	}
+
+	id, err := q.store.Save(ctx, j)
+	if err != nil {
+		return 0, fmt.Errorf("unable to persist the job, %v", err)
+	}
+
+	item := *j
+	item.Id = id
+
+	q.mu.Lock()
+	q.pending = append(q.pending, &item)
+	q.mu.Unlock()
+	q.cond.Signal()
+
+	return id, nil
+}
+
+func (q *queue) Cancel(ctx context.Context, typ dao.JobType, targetId int64) (bool, error) {
+	q.mu.Lock()
+	for _, r := range q.running {
+		if r.job.Type == typ && r.job.TargetId == targetId {
+			atomic.StoreInt32(r.cancelled, 1)
+			r.cancel()
+			q.mu.Unlock()
+			return true, nil
+		}
+	}
+
+	var cancelled *dao.Job
+	for i, j := range q.pending {
+		if j.Type == typ && j.TargetId == targetId {
+			cancelled = j
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			break
+		}
+	}
+	q.mu.Unlock()
+
+	if cancelled == nil {
+		return false, nil
+	}
+
+	if err := q.store.Remove(ctx, cancelled.Id); err != nil {
+		return true, fmt.Errorf("unable to remove cancelled job %d, %v", cancelled.Id, err)
+	}
+
+	return true, nil
+}
+
+func (q *queue) Stop() {
+	q.mu.Lock()
+	q.stopped = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+
+	if q.cancel != nil {
+		q.cancel()
+	}
+
+	q.wg.Wait()
+}
+
+func (q *queue) work(ctx context.Context, handler Handler) {
+	defer q.wg.Done()
+	for {
+		r, ok := q.next(ctx)
+		if !ok {
+			return
+		}
+
+		q.run(ctx, handler, r)
+	}
+}
+
+// next blocks until a job is available and marks it as running, it returns false once the queue is stopped.
+func (q *queue) next(ctx context.Context) (*runningJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.pending) == 0 && !q.stopped {
+		q.cond.Wait()
+	}
+
+	if q.stopped {
+		return nil, false
+	}
+
+	j := q.pending[0]
+	q.pending[0] = nil
+	q.pending = q.pending[1:]
+
+	r := &runningJob{job: j, cancelled: new(int32)}
+	r.ctx, r.cancel = context.WithCancel(context.WithValue(ctx, cancelledKey{}, r.cancelled))
+	q.running[j.Id] = r
+
+	return r, true
+}
+
+func (q *queue) run(ctx context.Context, handler Handler, r *runningJob) {
+	j := r.job
+	jobCtx := r.ctx
+	defer func() {
+		q.mu.Lock()
+		delete(q.running, j.Id)
+		q.mu.Unlock()
+		r.cancel()
+
+		if p := recover(); p != nil {
+			logrus.Errorf("%s job %d panicked, %v", j.Type, j.Id, p)
+			q.done(j)
+		}
+	}()
+
+	logrus.Infof("Running %s job %d for %q", j.Type, j.Id, j.Url)
+	err := handler(jobCtx, j)
+	if Cancelled(jobCtx) {
+		logrus.Infof("%s job %d cancelled", j.Type, j.Id)
+		q.done(j)
+		return
+	}
+
+	if ctx.Err() != nil {
+		logrus.Infof("%s job %d interrupted, it will be resumed on the next start", j.Type, j.Id)
+		return
+	}
+
+	if err != nil {
+		logrus.Errorf("%s job %d failed, %v", j.Type, j.Id, err)
+	}
+
+	q.done(j)
+}
+
+func (q *queue) done(j *dao.Job) {
+	// the queue context may be cancelled already, the job must still leave the store
+	if err := q.store.Remove(context.Background(), j.Id); err != nil {
+		logrus.Errorf("unable to remove finished job %d, %v", j.Id, err)
+	}
+}
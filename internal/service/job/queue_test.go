@@ -0,0 +1,239 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/dao"
+	"github.com/DiLRandI/web-analyser/internal/repository/mem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_queue_should_run_pushed_jobs_and_remove_them_from_store(t *testing.T) {
+	store := mem.NewJobInMemory()
+	sut := NewQueue(store, 2)
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+	var handled int64
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		defer wg.Done()
+		atomic.AddInt64(&handled, 1)
+		if j.TargetId == 2 {
+			return errors.New("test failure")
+		}
+		return nil
+	}))
+
+	for i := int64(1); i <= 3; i++ {
+		_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: i})
+		assert.NoError(t, err)
+	}
+
+	wg.Wait()
+	sut.Stop()
+
+	assert.Equal(t, int64(3), atomic.LoadInt64(&handled))
+	jobs, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func Test_queue_should_not_run_more_jobs_than_workers(t *testing.T) {
+	sut := NewQueue(mem.NewJobInMemory(), 2)
+	wg := sync.WaitGroup{}
+	wg.Add(6)
+	var running, maxRunning int64
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		defer wg.Done()
+		n := atomic.AddInt64(&running, 1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt64(&running, -1)
+		return nil
+	}))
+
+	for i := 0; i < 6; i++ {
+		_, err := sut.Push(context.Background(), &dao.Job{})
+		assert.NoError(t, err)
+	}
+
+	wg.Wait()
+	sut.Stop()
+
+	assert.LessOrEqual(t, atomic.LoadInt64(&maxRunning), int64(2))
+}
+
+func Test_queue_should_resume_pending_jobs_on_start(t *testing.T) {
+	store := mem.NewJobInMemory()
+	_, err := store.Save(context.Background(), &dao.Job{TargetId: 1})
+	require.NoError(t, err)
+
+	sut := NewQueue(store, 1)
+	handled := make(chan int64, 1)
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		handled <- j.TargetId
+		return nil
+	}))
+
+	select {
+	case id := <-handled:
+		assert.Equal(t, int64(1), id)
+	case <-time.After(time.Second):
+		assert.Fail(t, "pending job was not resumed")
+	}
+	sut.Stop()
+}
+
+func Test_queue_stop_should_keep_interrupted_jobs_in_store(t *testing.T) {
+	store := mem.NewJobInMemory()
+	sut := NewQueue(store, 1)
+	started := make(chan struct{})
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	_, err := sut.Push(context.Background(), &dao.Job{TargetId: 1})
+	require.NoError(t, err)
+	<-started
+	sut.Stop()
+
+	jobs, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	_, err = sut.Push(context.Background(), &dao.Job{})
+	assert.ErrorIs(t, err, QueueStoppedErr)
+}
+
+func Test_queue_cancel_should_remove_pending_job(t *testing.T) {
+	store := mem.NewJobInMemory()
+	sut := NewQueue(store, 1)
+
+	_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 1})
+	require.NoError(t, err)
+
+	ok, err := sut.Cancel(context.Background(), dao.JobTypeAnalysis, 1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	jobs, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	ok, err = sut.Cancel(context.Background(), dao.JobTypeAnalysis, 1)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func Test_queue_cancel_should_cancel_running_job_context(t *testing.T) {
+	store := mem.NewJobInMemory()
+	sut := NewQueue(store, 1)
+	started := make(chan struct{})
+	cancelled := make(chan bool, 1)
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		close(started)
+		<-ctx.Done()
+		cancelled <- Cancelled(ctx)
+		return ctx.Err()
+	}))
+
+	_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 1})
+	require.NoError(t, err)
+	<-started
+
+	ok, err := sut.Cancel(context.Background(), dao.JobTypeAnalysis, 1)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, <-cancelled)
+
+	sut.Stop()
+	jobs, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func Test_queue_should_run_a_job_pushed_before_start_once(t *testing.T) {
+	sut := NewQueue(mem.NewJobInMemory(), 2)
+	_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 1})
+	require.NoError(t, err)
+
+	var handled int64
+	done := make(chan struct{})
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		if atomic.AddInt64(&handled, 1) == 1 {
+			close(done)
+		}
+		return nil
+	}))
+	<-done
+	// a duplicate would run right after the first one
+	time.Sleep(50 * time.Millisecond)
+	sut.Stop()
+
+	assert.Equal(t, int64(1), atomic.LoadInt64(&handled))
+}
+
+func Test_queue_should_accept_jobs_after_a_restart(t *testing.T) {
+	sut := NewQueue(mem.NewJobInMemory(), 1)
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error { return nil }))
+	sut.Stop()
+
+	_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 1})
+	assert.ErrorIs(t, err, QueueStoppedErr)
+
+	handled := make(chan int64, 1)
+	require.NoError(t, sut.Start(context.Background(), func(ctx context.Context, j *dao.Job) error {
+		handled <- j.TargetId
+		return nil
+	}))
+	defer sut.Stop()
+
+	_, err = sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), <-handled)
+}
+
+func Test_queue_should_fail_the_target_of_a_panicking_job(t *testing.T) {
+	store := mem.NewJobInMemory()
+	sut := NewQueue(store, 1)
+	failed := make(chan int64, 1)
+	require.NoError(t, sut.Start(context.Background(), Recover(
+		func(ctx context.Context, j *dao.Job) error {
+			panic("test panic")
+		},
+		func(ctx context.Context, j *dao.Job) {
+			failed <- j.TargetId
+		},
+	)))
+
+	_, err := sut.Push(context.Background(), &dao.Job{Type: dao.JobTypeAnalysis, TargetId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), <-failed)
+	sut.Stop()
+
+	jobs, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func Test_recover_should_return_an_error_for_a_panic(t *testing.T) {
+	sut := Recover(func(ctx context.Context, j *dao.Job) error {
+		panic("test panic")
+	}, func(ctx context.Context, j *dao.Job) {})
+
+	err := sut(context.Background(), &dao.Job{})
+
+	assert.ErrorIs(t, err, JobPanickedErr)
+}
@@ -0,0 +1,144 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxIdleBuckets is the number of host buckets kept before the full ones are dropped, a full bucket
+// is the same as a new one.
+const maxIdleBuckets = 1024
+
+// Limiter bounds the outgoing requests with a token bucket per host and a cap on the requests
+// in flight across all hosts. It is safe for concurrent use, share one instance between the users.
+type Limiter interface {
+	// Acquire waits for a token of the host and then for a free slot, release must be called
+	// once the request is done. It fails only when the ctx is done.
+	Acquire(ctx context.Context, host string) (release func(), err error)
+}
+
+type Config struct {
+	// RatePerHost is the sustained number of requests per second to a single host, 0 means unlimited.
+	RatePerHost float64
+	// BurstPerHost is the number of requests a host gets before the rate applies, at least 1.
+	BurstPerHost int
+	// MaxConcurrent caps the requests in flight across all hosts, 0 means unlimited.
+	MaxConcurrent int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type limiter struct {
+	config Config
+	slots  chan struct{}
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func New(config *Config) Limiter {
+	l := &limiter{
+		config:  *config,
+		buckets: map[string]*bucket{},
+	}
+	if l.config.BurstPerHost < 1 {
+		l.config.BurstPerHost = 1
+	}
+
+	if config.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+
+	return l
+}
+
+func (l *limiter) Acquire(ctx context.Context, host string) (func(), error) {
+	if err := l.take(ctx, host); err != nil {
+		return nil, err
+	}
+
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			<-l.slots
+		})
+	}, nil
+}
+
+// take removes a token from the bucket of the host, waiting for the bucket to refill when it is empty.
+func (l *limiter) take(ctx context.Context, host string) error {
+	if l.config.RatePerHost <= 0 {
+		return nil
+	}
+
+	for {
+		wait := l.reserve(host)
+		if wait == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token when one is available, otherwise it returns the time until the next token.
+func (l *limiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	burst := float64(l.config.BurstPerHost)
+	b, ok := l.buckets[host]
+	if !ok {
+		l.prune(now)
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[host] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.config.RatePerHost
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / l.config.RatePerHost * float64(time.Second))
+}
+
+// prune drops the buckets which refilled, must be called holding the lock.
+func (l *limiter) prune(now time.Time) {
+	if len(l.buckets) < maxIdleBuckets {
+		return
+	}
+
+	burst := float64(l.config.BurstPerHost)
+	for host, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.config.RatePerHost >= burst {
+			delete(l.buckets, host)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_acquire_should_allow_the_burst_then_the_rate_per_host(t *testing.T) {
+	sut := New(&Config{RatePerHost: 20, BurstPerHost: 2})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := sut.Acquire(context.Background(), "test.com")
+		require.NoError(t, err)
+		release()
+	}
+
+	// 2 requests of the burst and 2 more at 20 requests per second
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func Test_acquire_should_not_share_the_buckets_between_hosts(t *testing.T) {
+	sut := New(&Config{RatePerHost: 0.1, BurstPerHost: 1})
+
+	for _, host := range []string{"a.com", "b.com", "c.com"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		release, err := sut.Acquire(ctx, host)
+		cancel()
+		require.NoError(t, err)
+		release()
+	}
+}
+
+func Test_acquire_should_stop_waiting_when_the_ctx_is_done(t *testing.T) {
+	sut := New(&Config{RatePerHost: 0.1, BurstPerHost: 1})
+	release, err := sut.Acquire(context.Background(), "test.com")
+	require.NoError(t, err)
+	release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = sut.Acquire(ctx, "test.com")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_acquire_should_cap_the_requests_in_flight(t *testing.T) {
+	sut := New(&Config{MaxConcurrent: 2})
+	inFlight := int32(0)
+	maxInFlight := int32(0)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := sut.Acquire(context.Background(), "test.com")
+			assert.NoError(t, err)
+			defer release()
+
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+func Test_release_should_free_the_slot_only_once(t *testing.T) {
+	sut := New(&Config{MaxConcurrent: 1})
+	release, err := sut.Acquire(context.Background(), "test.com")
+	require.NoError(t, err)
+	release()
+	release()
+
+	release, err = sut.Acquire(context.Background(), "test.com")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = sut.Acquire(ctx, "other.com")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	release()
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -9,136 +10,311 @@ import (
 	"github.com/DiLRandI/web-analyser/internal/dao"
 	"github.com/DiLRandI/web-analyser/internal/dto"
 	"github.com/DiLRandI/web-analyser/internal/repository"
-	"github.com/DiLRandI/web-analyser/internal/repository/mem"
+	"github.com/DiLRandI/web-analyser/internal/service/job"
 	"github.com/DiLRandI/web-analyser/internal/service/webpage"
-	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
 	"github.com/sirupsen/logrus"
 )
 
 type Processor interface {
+	// Start starts processing the queued analyses, including the ones left over from a previous run.
+	Start(ctx context.Context) error
+	// Stop interrupts the running analyses and waits for them, they are resumed on the next Start.
+	Stop()
 	ProcessPage(ctx context.Context, req *dto.AnalysesRequest) (*dto.AnalysesResponse, error)
 	GetProcessResultFor(ctx context.Context, id int64) (*dto.ResultResponse, error)
-	GetProcessResults(ctx context.Context) ([]*dto.ResultResponse, error)
+	// GetProcessResults returns a page of the analysis results and the total number of matching results.
+	GetProcessResults(ctx context.Context, q *dto.ResultsQuery) ([]*dto.ResultResponse, int, error)
+	// GetProcessLinksFor returns a page of the links found by the analysis and the total number of matching links.
+	GetProcessLinksFor(ctx context.Context, id int64, q *dto.LinksQuery) ([]*dto.LinkResponse, int, error)
+	// CancelProcess stops a queued or running analysis, the analysis ends up in the Cancelled state.
+	CancelProcess(ctx context.Context, id int64) error
+	// RemoveProcessResult cancels the analysis if it is still in progress and deletes it.
+	RemoveProcessResult(ctx context.Context, id int64) error
+	// ProcessSite queues a crawl of the site, every crawled page gets its own analysis.
+	ProcessSite(ctx context.Context, req *dto.CrawlRequest) (*dto.AnalysesResponse, error)
+	// GetSiteResultFor returns the site report of the crawl, it is updated after every crawled page.
+	GetSiteResultFor(ctx context.Context, id int64) (*dto.CrawlResponse, error)
 }
 
 type processor struct {
 	downloader webpage.Downloader
 	analyserFn func() webpage.Analyser
 	result     repository.Results
+	crawls     repository.Crawls
+	queue      job.Queue
 }
 
 func NewProcessor(downloader webpage.Downloader,
 	analyserFn func() webpage.Analyser,
-	result repository.Results) Processor {
+	result repository.Results,
+	crawls repository.Crawls,
+	queue job.Queue) Processor {
 	return &processor{
 		downloader: downloader,
 		analyserFn: analyserFn,
 		result:     result,
+		crawls:     crawls,
+		queue:      queue,
 	}
 }
 
+func (s *processor) Start(ctx context.Context) error {
+	return s.queue.Start(ctx, job.Recover(s.runJob, s.failJob))
+}
+
+func (s *processor) Stop() {
+	s.queue.Stop()
+}
+
 func (s *processor) ProcessPage(
 	ctx context.Context, req *dto.AnalysesRequest,
 ) (*dto.AnalysesResponse, error) {
 	if req.WebUrl == "" {
-		return nil, fmt.Errorf("WebUrl is required.")
+		return nil, &ValidationError{msg: "WebUrl is required."}
 	}
 
-	m, err := s.downloader.Download(ctx, req.WebUrl)
-	if err != nil {
-		return nil, err
+	if _, ok := normalizeUrl(req.WebUrl); !ok {
+		return nil, &ValidationError{msg: fmt.Sprintf("WebUrl %q is not a valid http(s) url", req.WebUrl)}
 	}
 
-	if m.Content == nil {
-		return nil, fmt.Errorf("there is no content to process further")
+	requested := time.Now()
+	analysis := &dao.Analyses{
+		Url:           req.WebUrl,
+		Requested:     requested,
+		ProcessStatus: &dao.ProcessStatusQueued,
 	}
-
-	id, err := s.result.Save(ctx, &dao.Analyses{
-		Url:           m.Url,
-		Requested:     time.Now(),
-		ProcessStatus: &dao.ProcessStatusCreated,
-	})
+	id, err := s.result.Save(ctx, analysis)
 	if err != nil {
 		return nil, err
 	}
 
-	go s.bgProcess(id, m)
+	if _, err := s.queue.Push(ctx, &dao.Job{
+		Type:     dao.JobTypeAnalysis,
+		TargetId: id,
+		Url:      req.WebUrl,
+		Created:  requested,
+	}); err != nil {
+		s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusFailed)
+		return nil, err
+	}
 
 	return &dto.AnalysesResponse{Id: id}, nil
 }
 
-func (s *processor) GetProcessResults(ctx context.Context) ([]*dto.ResultResponse, error) {
-	results, err := s.result.GetAll(ctx)
+func (s *processor) GetProcessResults(
+	ctx context.Context, q *dto.ResultsQuery,
+) ([]*dto.ResultResponse, int, error) {
+	results, total, err := s.result.Query(ctx, &dao.AnalysesQuery{
+		ProcessStatus: q.ProcessStatus,
+		UrlContains:   q.Url,
+		RequestedFrom: q.RequestedFrom,
+		RequestedTo:   q.RequestedTo,
+		HasLoginForm:  q.HasLoginForm,
+		SortBy:        dao.AnalysesSort(q.SortBy),
+		Descending:    q.Descending,
+		Offset:        q.Offset(),
+		Limit:         q.Limit,
+	})
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	res := []*dto.ResultResponse{}
 	for _, r := range results {
-		m := &dto.ResultResponse{}
-		m.Id = r.Id
-		m.Url = r.Url
-		m.Requested = r.Requested
-		m.Completed = r.Completed
-		m.ProcessStatus = string(*r.ProcessStatus)
-		m.Title = r.Title
-		m.Headings = r.Headings
-		m.InternalLinkCount = r.InternalLinkCount
-		m.ExternalLinkCount = r.ExternalLinkCount
-		m.ActiveLinkCount = r.ActiveLinkCount
-		m.InactiveLinkCount = r.InactiveLinkCount
-		m.PageVersion = r.PageVersion
-		m.HasLoginForm = r.HasLoginForm
-
-		res = append(res, m)
+		res = append(res, toResultResponse(r))
 	}
 
-	return res, nil
+	return res, total, nil
 }
 
 func (s *processor) GetProcessResultFor(ctx context.Context, id int64) (*dto.ResultResponse, error) {
 	result, err := s.result.Get(ctx, id)
 	if err != nil {
-		if errors.Is(err, mem.ResultNotFoundErr) {
+		if errors.Is(err, repository.ResultNotFoundErr) {
 			return nil, &NotFoundError{msg: err.Error()}
 		}
 
 		return nil, err
 	}
 
-	res := &dto.ResultResponse{}
-	res.Id = result.Id
-	res.Url = result.Url
-	res.Requested = result.Requested
-	res.Completed = result.Completed
-	res.ProcessStatus = string(*result.ProcessStatus)
-	res.Title = result.Title
-	res.Headings = result.Headings
-	res.InternalLinkCount = result.InternalLinkCount
-	res.ExternalLinkCount = result.ExternalLinkCount
-	res.ActiveLinkCount = result.ActiveLinkCount
-	res.InactiveLinkCount = result.InactiveLinkCount
-	res.PageVersion = result.PageVersion
-	res.HasLoginForm = result.HasLoginForm
+	return toResultResponse(result), nil
+}
+
+func (s *processor) GetProcessLinksFor(
+	ctx context.Context, id int64, q *dto.LinksQuery,
+) ([]*dto.LinkResponse, int, error) {
+	links, total, err := s.result.GetLinks(ctx, id, &dao.LinkQuery{
+		LinkStatus:     q.Status,
+		IsInternal:     q.IsInternal,
+		HttpStatusCode: q.HttpStatusCode,
+		Offset:         q.Offset(),
+		Limit:          q.Limit,
+	})
+	if err != nil {
+		if errors.Is(err, repository.ResultNotFoundErr) {
+			return nil, 0, &NotFoundError{msg: err.Error()}
+		}
+
+		return nil, 0, err
+	}
+
+	res := []*dto.LinkResponse{}
+	for _, l := range links {
+		res = append(res, &dto.LinkResponse{
+			Name:           l.Name,
+			Url:            l.Url,
+			ResolvedUrl:    l.ResolvedUrl,
+			Type:           l.Type,
+			IsInternal:     l.IsInternal,
+			LinkStatus:     l.LinkStatus,
+			HttpStatusCode: l.HttpStatusCode,
+		})
+	}
+
+	return res, total, nil
+}
+
+func (s *processor) CancelProcess(ctx context.Context, id int64) error {
+	analysis, err := s.result.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ResultNotFoundErr) {
+			return &NotFoundError{msg: err.Error()}
+		}
+
+		return err
+	}
+
+	if isFinished(analysis.ProcessStatus) {
+		return &ConflictError{
+			msg: fmt.Sprintf("analysis %d is already %s", id, *analysis.ProcessStatus),
+		}
+	}
+
+	found, err := s.queue.Cancel(ctx, dao.JobTypeAnalysis, id)
+	if err != nil {
+		return err
+	}
+
+	// a running job updates its own status once it notices the cancellation
+	if !found || *analysis.ProcessStatus != dao.ProcessStatusRunning {
+		s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusCancelled)
+	}
+
+	return nil
+}
+
+func (s *processor) RemoveProcessResult(ctx context.Context, id int64) error {
+	if _, err := s.queue.Cancel(ctx, dao.JobTypeAnalysis, id); err != nil {
+		return err
+	}
+
+	if err := s.result.Remove(ctx, id); err != nil {
+		if errors.Is(err, repository.ResultNotFoundErr) {
+			return &NotFoundError{msg: err.Error()}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *processor) runJob(ctx context.Context, j *dao.Job) error {
+	switch j.Type {
+	case dao.JobTypeAnalysis:
+		return s.analyse(ctx, j.TargetId)
+	case dao.JobTypeCrawl:
+		return s.crawl(ctx, j.TargetId)
+	default:
+		return fmt.Errorf("unknown job type %q", j.Type)
+	}
+}
+
+// failJob marks the target of a panicked job as failed, the error path of the job would have done it.
+func (s *processor) failJob(ctx context.Context, j *dao.Job) {
+	switch j.Type {
+	case dao.JobTypeAnalysis:
+		s.failAnalysis(ctx, j.TargetId)
+	case dao.JobTypeCrawl:
+		crawl, err := s.crawls.Get(ctx, j.TargetId)
+		if err != nil {
+			logrus.Errorf("unable to fail crawl %d, %v", j.TargetId, err)
+			return
+		}
 
-	return res, nil
+		// the page being analysed is left running too
+		for _, p := range crawl.Pages {
+			if p.AnalysisId != 0 && !isFinished(p.ProcessStatus) {
+				s.failAnalysis(ctx, p.AnalysisId)
+			}
+		}
+		s.updateCrawlStatus(ctx, j.TargetId, crawl, dao.ProcessStatusFailed)
+	}
 }
 
-func (s *processor) bgProcess(id int64, m *model.DownloadedWebpage) {
-	logrus.Infof("Starting analysis for url %q", m.Url)
-	ctx := context.Background()
+func (s *processor) failAnalysis(ctx context.Context, id int64) {
 	analysis, err := s.result.Get(ctx, id)
 	if err != nil {
-		logrus.Error(err)
+		logrus.Errorf("unable to fail analysis %d, %v", id, err)
 		return
 	}
 
+	if !isFinished(analysis.ProcessStatus) {
+		s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusFailed)
+	}
+}
+
+func (s *processor) analyse(ctx context.Context, id int64) error {
+	analysis, err := s.result.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if isFinished(analysis.ProcessStatus) {
+		logrus.Infof("Skipping analysis %d, it is already %s", id, *analysis.ProcessStatus)
+		return nil
+	}
+
+	logrus.Infof("Starting analysis for url %q", analysis.Url)
+	s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusRunning)
+
+	m, err := s.downloader.Download(ctx, analysis.Url)
+	if errors.Is(err, webpage.BlockedByRobotsErr) {
+		logrus.Infof("Skipping analysis %d, %v", id, err)
+		if s.cancelledMeanwhile(ctx, id) {
+			return nil
+		}
+		analysis.Completed = timePtr(time.Now())
+		s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusBlocked)
+		return nil
+	}
+
+	if err != nil {
+		s.abort(ctx, id, analysis)
+		return err
+	}
+	analysis.FinalUrl = m.FinalUrl
+	analysis.Redirects = []*dao.Redirect{}
+	for _, r := range m.Redirects {
+		analysis.Redirects = append(analysis.Redirects, &dao.Redirect{
+			Url:        r.Url,
+			StatusCode: r.StatusCode,
+			Location:   r.Location,
+		})
+	}
+	analysis.StatusCode = m.StatusCode
+	analysis.Headers = m.Headers
+
 	svc := s.analyserFn()
 	pageResult, err := svc.AnalysePage(ctx, m)
 	if err != nil {
-		logrus.Error(err)
-		s.updateProcessStatus(ctx, id, analysis, dao.ProcessStatusFailed)
-		return
+		s.abort(ctx, id, analysis)
+		return err
+	}
+
+	if ctx.Err() != nil {
+		s.abort(ctx, id, analysis)
+		return ctx.Err()
 	}
 
 	logrus.Infof("Analysis completed, %+#v", pageResult)
@@ -150,12 +326,69 @@ func (s *processor) bgProcess(id int64, m *model.DownloadedWebpage) {
 	analysis.ExternalLinkCount = pageResult.ExternalLinkCount
 	analysis.ActiveLinkCount = pageResult.ActiveLinkCount
 	analysis.InactiveLinkCount = pageResult.InactiveLinkCount
+	analysis.BlockedLinkCount = pageResult.BlockedLinkCount
+	analysis.LinkTypeCounts = map[string]int{}
+	for t, c := range pageResult.LinkTypeCounts {
+		analysis.LinkTypeCounts[string(t)] = c
+	}
 	analysis.PageVersion = pageResult.PageVersion
 	analysis.HasLoginForm = pageResult.HasLoginForm
+	analysis.Sections = map[string]json.RawMessage{}
+	for name, section := range pageResult.Sections {
+		encoded, err := json.Marshal(section)
+		if err != nil {
+			logrus.Errorf("unable to encode the %s section of analysis id %d, %v", name, id, err)
+			continue
+		}
+		analysis.Sections[name] = encoded
+	}
+	analysis.Links = []*dao.Link{}
+	for _, l := range pageResult.Links {
+		analysis.Links = append(analysis.Links, &dao.Link{
+			Name:           l.Name,
+			Url:            l.Url,
+			ResolvedUrl:    l.ResolvedUrl,
+			Type:           string(l.Type),
+			IsInternal:     l.IsInternal,
+			LinkStatus:     string(l.LinkStatus),
+			HttpStatusCode: l.HttpStatusCode,
+		})
+	}
+
+	if s.cancelledMeanwhile(ctx, id) {
+		logrus.Infof("Dropping the result of analysis %d, it was cancelled", id)
+		return nil
+	}
 
 	logrus.Infof("updating the result, %+#v", analysis)
 	if err := s.result.Update(ctx, id, analysis); err != nil {
-		logrus.Errorf("unable to update the results, %v", err)
+		return fmt.Errorf("unable to update the results, %v", err)
+	}
+
+	return nil
+}
+
+// cancelledMeanwhile reports whether the analysis was cancelled while it was running. The page analyses
+// of a crawl do not run as jobs of their own, CancelProcess can only mark them as cancelled in the store.
+func (s *processor) cancelledMeanwhile(ctx context.Context, id int64) bool {
+	stored, err := s.result.Get(ctx, id)
+	if err != nil || stored.ProcessStatus == nil {
+		return false
+	}
+
+	return *stored.ProcessStatus == dao.ProcessStatusCancelled
+}
+
+// abort marks the analysis as failed, unless the job was cancelled or interrupted by a shutdown
+// in which case the analysis goes back to the queued state to be resumed on the next start.
+func (s *processor) abort(ctx context.Context, id int64, m *dao.Analyses) {
+	switch {
+	case job.Cancelled(ctx):
+		s.updateProcessStatus(context.Background(), id, m, dao.ProcessStatusCancelled)
+	case ctx.Err() != nil:
+		s.updateProcessStatus(context.Background(), id, m, dao.ProcessStatusQueued)
+	case !s.cancelledMeanwhile(ctx, id):
+		s.updateProcessStatus(ctx, id, m, dao.ProcessStatusFailed)
 	}
 }
 
@@ -169,6 +402,52 @@ func (s *processor) updateProcessStatus(
 
 }
 
+func toResultResponse(r *dao.Analyses) *dto.ResultResponse {
+	m := &dto.ResultResponse{}
+	m.Id = r.Id
+	m.Url = r.Url
+	m.Requested = r.Requested
+	m.Completed = r.Completed
+	m.ProcessStatus = string(*r.ProcessStatus)
+	m.FinalUrl = r.FinalUrl
+	m.Redirects = []*dto.RedirectResponse{}
+	for _, redirect := range r.Redirects {
+		m.Redirects = append(m.Redirects, &dto.RedirectResponse{
+			Url:        redirect.Url,
+			StatusCode: redirect.StatusCode,
+			Location:   redirect.Location,
+		})
+	}
+	m.StatusCode = r.StatusCode
+	m.Headers = r.Headers
+	m.Title = r.Title
+	m.Headings = r.Headings
+	m.InternalLinkCount = r.InternalLinkCount
+	m.ExternalLinkCount = r.ExternalLinkCount
+	m.ActiveLinkCount = r.ActiveLinkCount
+	m.InactiveLinkCount = r.InactiveLinkCount
+	m.BlockedLinkCount = r.BlockedLinkCount
+	m.LinkTypeCounts = r.LinkTypeCounts
+	m.PageVersion = r.PageVersion
+	m.HasLoginForm = r.HasLoginForm
+	m.Sections = r.Sections
+
+	return m
+}
+
+func isFinished(ps *dao.ProcessStatus) bool {
+	if ps == nil {
+		return false
+	}
+
+	switch *ps {
+	case dao.ProcessStatusCompleted, dao.ProcessStatusFailed, dao.ProcessStatusCancelled, dao.ProcessStatusBlocked:
+		return true
+	}
+
+	return false
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
@@ -0,0 +1,249 @@
+package webpage
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"golang.org/x/net/html"
+)
+
+// unlabelledInputTypes are the input types which don't need a label, their value or alt is their name.
+var unlabelledInputTypes = map[string]bool{
+	"hidden": true, "submit": true, "reset": true, "button": true, "image": true,
+}
+
+// accessibilityCheck audits the page for the common accessibility failures, every issue is located
+// on the element which causes it.
+type accessibilityCheck struct {
+	hasHtml  bool
+	lang     string
+	headings []*Token
+	ids      map[string]bool
+	// labelFor are the ids of the form fields named by a <label for>
+	labelFor   map[string]bool
+	labelDepth int
+	fields     []*formField
+	openLink   *namedElement
+	openButton *namedElement
+	issues     *issueList
+}
+
+type formField struct {
+	t        *Token
+	id       string
+	labelled bool
+}
+
+// namedElement is a link or button being read, its text is collected until the element ends.
+type namedElement struct {
+	t     *Token
+	named bool
+}
+
+func newAccessibilityCheck() Check {
+	return &accessibilityCheck{
+		ids:      map[string]bool{},
+		labelFor: map[string]bool{},
+		issues:   &issueList{list: []*model.Issue{}},
+	}
+}
+
+func (c *accessibilityCheck) Name() string {
+	return "accessibility"
+}
+
+func (c *accessibilityCheck) Token(t *Token) {
+	switch {
+	case t.IsStartTag():
+		c.startTag(t)
+	case t.Type == html.EndTagToken:
+		c.endTag(t.Data)
+	case t.Type == html.TextToken:
+		if strings.TrimSpace(t.Data) == "" {
+			return
+		}
+		if c.openLink != nil {
+			c.openLink.named = true
+		}
+		if c.openButton != nil {
+			c.openButton.named = true
+		}
+	}
+}
+
+func (c *accessibilityCheck) startTag(t *Token) {
+	if id, ok := t.AttrValue("id"); ok && id != "" {
+		if c.ids[id] {
+			c.issues.addAt("id-duplicate", model.SeverityWarning, t, "the id %q is used by more than one element", id)
+		}
+		c.ids[id] = true
+	}
+
+	switch t.Data {
+	case "html":
+		if !c.hasHtml {
+			c.hasHtml = true
+			lang, _ := t.AttrValue("lang")
+			c.lang = strings.TrimSpace(lang)
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.headings = append(c.headings, t)
+	case "img":
+		alt, hasAlt := t.AttrValue("alt")
+		if !hasAlt && !isHidden(t) {
+			c.issues.addAt("img-alt-missing", model.SeverityError, t, "the image has no alt text")
+		}
+		// the alt text of an image is the name of the link or button it is in
+		if strings.TrimSpace(alt) != "" {
+			c.nameOpenElements()
+		}
+	case "input":
+		typ, _ := t.AttrValue("type")
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		switch {
+		case typ == "image":
+			if _, ok := t.AttrValue("alt"); !ok {
+				c.issues.addAt("img-alt-missing", model.SeverityError, t, "the image button has no alt text")
+			}
+		case typ == "button":
+			if value, _ := t.AttrValue("value"); strings.TrimSpace(value) == "" && !hasAriaName(t) {
+				c.issues.addAt("button-text-empty", model.SeverityError, t, "the button has no text")
+			}
+		case !unlabelledInputTypes[typ]:
+			c.addField(t)
+		}
+	case "select", "textarea":
+		c.addField(t)
+	case "label":
+		if f, ok := t.AttrValue("for"); ok {
+			c.labelFor[f] = true
+		}
+		if t.Type == html.StartTagToken {
+			c.labelDepth++
+		}
+	case "a":
+		if _, ok := t.AttrValue("href"); !ok {
+			return
+		}
+		l := &namedElement{t: t, named: hasAriaName(t)}
+		if t.Type == html.SelfClosingTagToken {
+			c.closeLink(l)
+			return
+		}
+		c.openLink = l
+	case "button":
+		b := &namedElement{t: t, named: hasAriaName(t)}
+		if t.Type == html.SelfClosingTagToken {
+			c.closeButton(b)
+			return
+		}
+		c.openButton = b
+	}
+}
+
+func (c *accessibilityCheck) endTag(name string) {
+	switch name {
+	case "label":
+		if c.labelDepth > 0 {
+			c.labelDepth--
+		}
+	case "a":
+		c.closeLink(c.openLink)
+		c.openLink = nil
+	case "button":
+		c.closeButton(c.openButton)
+		c.openButton = nil
+	}
+}
+
+func (c *accessibilityCheck) addField(t *Token) {
+	id, _ := t.AttrValue("id")
+	c.fields = append(c.fields, &formField{
+		t:        t,
+		id:       id,
+		labelled: c.labelDepth > 0 || hasAriaName(t),
+	})
+}
+
+func (c *accessibilityCheck) nameOpenElements() {
+	if c.openLink != nil {
+		c.openLink.named = true
+	}
+	if c.openButton != nil {
+		c.openButton.named = true
+	}
+}
+
+func (c *accessibilityCheck) closeLink(l *namedElement) {
+	if l != nil && !l.named {
+		c.issues.addAt("link-text-empty", model.SeverityError, l.t, "the link has no text")
+	}
+}
+
+func (c *accessibilityCheck) closeButton(b *namedElement) {
+	if b != nil && !b.named {
+		c.issues.addAt("button-text-empty", model.SeverityError, b.t, "the button has no text")
+	}
+}
+
+func (c *accessibilityCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	// elements left open by the end of the document
+	c.endTag("a")
+	c.endTag("button")
+
+	section := &model.AccessibilitySection{
+		Lang:         c.lang,
+		HeadingOrder: []string{},
+	}
+
+	if c.lang == "" {
+		c.issues.add("lang-missing", model.SeverityError, 0, "the html element has no lang attribute")
+	}
+
+	for _, f := range c.fields {
+		if !f.labelled && (f.id == "" || !c.labelFor[f.id]) {
+			c.issues.addAt("input-label-missing", model.SeverityError, f.t, "the form field has no label")
+		}
+	}
+
+	previous := 0
+	for _, h := range c.headings {
+		section.HeadingOrder = append(section.HeadingOrder, h.Data)
+		level := int(h.Data[1] - '0')
+		if previous > 0 && level > previous+1 {
+			c.issues.addAt("heading-level-skipped", model.SeverityWarning, h,
+				"the h%d heading follows a h%d heading, skipping a level", level, previous)
+		}
+		previous = level
+	}
+
+	// the issues found on the fly and the ones found at the end are listed in document order
+	sort.SliceStable(c.issues.list, func(i, j int) bool {
+		return c.issues.list[i].Line < c.issues.list[j].Line
+	})
+	section.Issues = c.issues.list
+	analysis.Sections[c.Name()] = section
+
+	return nil
+}
+
+// hasAriaName reports whether the element is named by its attributes instead of its content.
+func hasAriaName(t *Token) bool {
+	for _, key := range []string{"aria-label", "aria-labelledby", "title"} {
+		if v, ok := t.AttrValue(key); ok && strings.TrimSpace(v) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isHidden reports whether the element is hidden from the assistive technologies.
+func isHidden(t *Token) bool {
+	hidden, _ := t.AttrValue("aria-hidden")
+	role, _ := t.AttrValue("role")
+
+	return strings.EqualFold(hidden, "true") || role == "presentation" || role == "none"
+}
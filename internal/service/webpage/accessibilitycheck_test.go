@@ -0,0 +1,75 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_accessibility_check_should_pass_an_accessible_page(t *testing.T) {
+	analysis, err := runCheck(newAccessibilityCheck(), "https://www.test.com", `<!DOCTYPE html>
+<html lang="en">
+<body>
+	<h1>Shop</h1>
+	<h2>Search</h2>
+	<form>
+		<label for="q">Search</label><input id="q" type="search">
+		<label>Size <select name="size"></select></label>
+		<textarea aria-label="Comment"></textarea>
+		<input type="hidden" name="token">
+		<input type="submit">
+		<button type="submit"><img src="go.png" alt="Go"></button>
+	</form>
+	<h2>Offers</h2>
+	<h3>Today</h3>
+	<img src="spacer.gif" alt="">
+	<a href="/offers">All the <b>offers</b></a>
+	<a href="/" title="Home"><img src="home.png" aria-hidden="true"></a>
+</body>
+</html>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.AccessibilitySection](t, analysis, "accessibility")
+
+	assert.Equal(t, "en", section.Lang)
+	assert.Equal(t, []string{"h1", "h2", "h2", "h3"}, section.HeadingOrder)
+	assert.Empty(t, section.Issues)
+}
+
+func Test_accessibility_check_should_locate_the_failures(t *testing.T) {
+	analysis, err := runCheck(newAccessibilityCheck(), "https://www.test.com", `<html>
+<body>
+<h1>Title</h1>
+<h4 id="main">Skipped</h4>
+<img src="logo.png">
+<form>
+<input id="email" type="email">
+<label for="other">Other</label>
+</form>
+<a href="/cart"> </a>
+<button></button>
+<div id="main"></div>
+<h2>Back</h2>
+</body>
+</html>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.AccessibilitySection](t, analysis, "accessibility")
+
+	assert.Equal(t, []*model.Issue{
+		{Code: "lang-missing", Severity: model.SeverityError, Message: "the html element has no lang attribute"},
+		{Code: "heading-level-skipped", Severity: model.SeverityWarning,
+			Message: "the h4 heading follows a h1 heading, skipping a level", Line: 4,
+			Element: `<h4 id="main">`},
+		{Code: "img-alt-missing", Severity: model.SeverityError, Message: "the image has no alt text", Line: 5,
+			Element: `<img src="logo.png">`},
+		{Code: "input-label-missing", Severity: model.SeverityError, Message: "the form field has no label", Line: 7,
+			Element: `<input id="email" type="email">`},
+		{Code: "link-text-empty", Severity: model.SeverityError, Message: "the link has no text", Line: 10,
+			Element: `<a href="/cart">`},
+		{Code: "button-text-empty", Severity: model.SeverityError, Message: "the button has no text", Line: 11,
+			Element: `<button>`},
+		{Code: "id-duplicate", Severity: model.SeverityWarning,
+			Message: `the id "main" is used by more than one element`, Line: 12, Element: `<div id="main">`},
+	}, section.Issues)
+}
@@ -11,61 +11,94 @@ import (
 	mc "github.com/DiLRandI/web-analyser/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// runCheck runs a single check against the content of the document served from documentUrl.
+func runCheck(check Check, documentUrl, content string) (*model.Analysis, error) {
+	return runChecks(&model.DownloadedWebpage{Url: documentUrl, Content: []byte(content)}, check)
+}
+
+// runChecks runs the checks against the page in a single pass like the analyser, the checks before
+// the last one fill the analysis fields it reads. The error is the one of the last check.
+func runChecks(page *model.DownloadedWebpage, checks ...Check) (*model.Analysis, error) {
+	doc, err := newDocument(page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tokenize(doc, checks); err != nil {
+		return nil, err
+	}
+
+	analysis := &model.Analysis{Sections: map[string]any{}}
+	for _, c := range checks {
+		err = c.Finish(context.Background(), doc, analysis)
+	}
+
+	return analysis, err
+}
+
+// sectionOf returns the section the check named name added to the analysis.
+func sectionOf[T any](t *testing.T, analysis *model.Analysis, name string) T {
+	section, ok := analysis.Sections[name].(T)
+	require.True(t, ok, "the analysis has no %s section", name)
+
+	return section
+}
+
 func Test_page_version_for_html5(t *testing.T) {
-	sut := &analyser{}
 	content := `<!DOCTYPE html>`
-	version, err := sut.pageVersion(context.Background(), []byte(content))
+	analysis, err := runCheck(newVersionCheck(), "http://www.test.com", content)
+	version := analysis.PageVersion
 
 	assert.NoError(t, err)
-	assert.Equal(t, "HTML5 and beyond", version)
+	assert.Equal(t, "HTML5", version)
 }
 
-func Test_page_version_return_an_error_when_doctype_element_is_missing(t *testing.T) {
-	sut := &analyser{}
+func Test_page_version_is_none_when_doctype_element_is_missing(t *testing.T) {
 	content := `<html lang="en">
 	</html>`
-	version, err := sut.pageVersion(context.Background(), []byte(content))
+	analysis, err := runCheck(newVersionCheck(), "http://www.test.com", content)
+	version := analysis.PageVersion
 
-	assert.Empty(t, version)
-	assert.ErrorContains(t, err, "!Doctype node is not found in the document")
+	assert.NoError(t, err)
+	assert.Equal(t, "none", version)
 }
 
 func Test_page_version_for_html_4_01_strict(t *testing.T) {
-	sut := &analyser{}
 	content := `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN"
 	"http://www.w3.org/TR/html4/strict.dtd">`
-	version, err := sut.pageVersion(context.Background(), []byte(content))
+	analysis, err := runCheck(newVersionCheck(), "http://www.test.com", content)
+	version := analysis.PageVersion
 
 	assert.NoError(t, err)
-	assert.Equal(t, "DTD HTML 4.01", version)
+	assert.Equal(t, "HTML 4.01 Strict", version)
 }
 
-func Test_page_version_return_an_error_when_doctype_content_is_malformed(t *testing.T) {
-	sut := &analyser{}
+func Test_page_version_is_unknown_when_doctype_content_is_malformed(t *testing.T) {
 	malformedContent := `HTML PUBLIC "//W3C//DTD HTML 4.01//EN"
 	"http://www.w3.org/TR/html4/strict.dtd"`
 	content := fmt.Sprintf(`<!DOCTYPE %s>`, malformedContent)
-	version, err := sut.pageVersion(context.Background(), []byte(content))
+	analysis, err := runCheck(newVersionCheck(), "http://www.test.com", content)
+	version := analysis.PageVersion
 
-	assert.Empty(t, version)
-	assert.ErrorContains(t, err, fmt.Sprintf("Unable to parse the Doctype node %q", malformedContent))
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown", version)
 }
 
-func Test_page_version_return_an_error_when_doctype_content_version_is_malformed(t *testing.T) {
-	sut := &analyser{}
+func Test_page_version_is_unknown_when_doctype_content_version_is_malformed(t *testing.T) {
 	malformedContent := `HTML PUBLIC "-//W3C"
 	"http://www.w3.org/TR/html4/strict.dtd"`
 	content := fmt.Sprintf(`<!DOCTYPE %s>`, malformedContent)
-	version, err := sut.pageVersion(context.Background(), []byte(content))
+	analysis, err := runCheck(newVersionCheck(), "http://www.test.com", content)
+	version := analysis.PageVersion
 
-	assert.Empty(t, version)
-	assert.ErrorContains(t, err, fmt.Sprintf("Unable to parse the Doctype node %q", malformedContent))
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown", version)
 }
 
 func Test_page_title_returns_for_valid_title(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<head>
@@ -75,14 +108,14 @@ func Test_page_title_returns_for_valid_title(t *testing.T) {
 		
 	</body>
 	</html>`
-	title, err := sut.pageTitle(context.Background(), []byte(content))
+	analysis, err := runCheck(newTitleCheck(), "http://www.test.com", content)
+	title := analysis.Title
 
 	assert.NoError(t, err)
 	assert.Equal(t, title, "Test Title")
 }
 
 func Test_page_title_returns_empty_for_empty_title_node(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<head>
@@ -92,14 +125,14 @@ func Test_page_title_returns_empty_for_empty_title_node(t *testing.T) {
 		
 	</body>
 	</html>`
-	title, err := sut.pageTitle(context.Background(), []byte(content))
+	analysis, err := runCheck(newTitleCheck(), "http://www.test.com", content)
+	title := analysis.Title
 
 	assert.NoError(t, err)
 	assert.Empty(t, title)
 }
 
 func Test_page_title_returns_error_if_title_node_not_found(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<head>
@@ -108,14 +141,14 @@ func Test_page_title_returns_error_if_title_node_not_found(t *testing.T) {
 		
 	</body>
 	</html>`
-	title, err := sut.pageTitle(context.Background(), []byte(content))
+	analysis, err := runCheck(newTitleCheck(), "http://www.test.com", content)
+	title := analysis.Title
 
 	assert.Empty(t, title)
 	assert.ErrorContains(t, err, "title node not found")
 }
 
 func Test_page_title_returns_error_if_title_element_is_not_found_in_head_tag(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<head>
@@ -124,28 +157,28 @@ func Test_page_title_returns_error_if_title_element_is_not_found_in_head_tag(t *
 		<title>Invalid title in Body</title>
 	</body>
 	</html>`
-	title, err := sut.pageTitle(context.Background(), []byte(content))
+	analysis, err := runCheck(newTitleCheck(), "http://www.test.com", content)
+	title := analysis.Title
 
 	assert.Empty(t, title)
 	assert.ErrorContains(t, err, "title node not found")
 }
 
 func Test_page_title_returns_error_if_head_element_is_missing_in_the_document(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<body>
 		<title>Invalid title in Body</title>
 	</body>
 	</html>`
-	title, err := sut.pageTitle(context.Background(), []byte(content))
+	analysis, err := runCheck(newTitleCheck(), "http://www.test.com", content)
+	title := analysis.Title
 
 	assert.Empty(t, title)
 	assert.ErrorContains(t, err, "head element not found in the document")
 }
 
 func Test_page_heading_count_should_return_valid_heading_count(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 	<body>
@@ -159,14 +192,14 @@ func Test_page_heading_count_should_return_valid_heading_count(t *testing.T) {
 		"h5": 0,
 		"h6": 0,
 	}
-	headings, err := sut.headingDetails(context.Background(), []byte(content))
+	analysis, err := runCheck(newHeadingsCheck(), "http://www.test.com", content)
+	headings := analysis.Headings
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, headings, expected)
 }
 
 func Test_page_heading_count_should_return_valid_heading_count_for_each_heading(t *testing.T) {
-	sut := &analyser{}
 	content := `
 	<html lang="en">
 
@@ -188,7 +221,8 @@ func Test_page_heading_count_should_return_valid_heading_count_for_each_heading(
 		"h5": 1,
 		"h6": 1,
 	}
-	headings, err := sut.headingDetails(context.Background(), []byte(content))
+	analysis, err := runCheck(newHeadingsCheck(), "http://www.test.com", content)
+	headings := analysis.Headings
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, headings, expected)
@@ -289,8 +323,8 @@ func Test_login_form(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			sut := &analyser{}
-			actRes, actErr := sut.hasLoginForm(context.Background(), []byte(tc.input))
+			analysis, actErr := runCheck(newFormsCheck(DefaultFormKeywords())(), "http://www.test.com", tc.input)
+			actRes := analysis.HasLoginForm
 
 			if tc.expErr == nil {
 				assert.NoError(t, actErr)
@@ -345,30 +379,19 @@ func Test_link_details(t *testing.T) {
 			</head>
 			
 			<body>
+				<h2 id="id-test">Test</h2>
 			</body>
 				<a href="#id-test">Go to test</a>
 			</html>`,
 			expErr: nil,
 			expRes: []*model.Link{
 				{
-					Name:           "Go to test",
-					Url:            "#id-test",
-					IsInternal:     true,
-					LinkStatus:     model.LinkStatusActive,
-					HttpStatusCode: http.StatusOK,
-				},
-			},
-			mockClient: []struct {
-				getUrl string
-				res    *http.Response
-				err    error
-			}{
-				{
-					getUrl: "http://www.test.com/#id-test",
-					res: &http.Response{
-						StatusCode: http.StatusOK,
-					},
-					err: nil,
+					Name:        "Go to test",
+					Url:         "#id-test",
+					ResolvedUrl: "http://www.test.com#id-test",
+					Type:        model.LinkTypeFragment,
+					IsInternal:  true,
+					LinkStatus:  model.LinkStatusActive,
 				},
 			},
 		},
@@ -391,6 +414,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "/test",
+					ResolvedUrl:    "http://www.test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     true,
 					LinkStatus:     model.LinkStatusActive,
 					HttpStatusCode: http.StatusOK,
@@ -405,6 +430,7 @@ func Test_link_details(t *testing.T) {
 					getUrl: mock.Anything,
 					res: &http.Response{
 						StatusCode: http.StatusOK,
+						Body:       http.NoBody,
 					},
 					err: nil,
 				},
@@ -429,6 +455,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "http://www.test.com/test",
+					ResolvedUrl:    "http://www.test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     true,
 					LinkStatus:     model.LinkStatusActive,
 					HttpStatusCode: http.StatusOK,
@@ -443,6 +471,7 @@ func Test_link_details(t *testing.T) {
 					getUrl: mock.Anything,
 					res: &http.Response{
 						StatusCode: http.StatusOK,
+						Body:       http.NoBody,
 					},
 					err: nil,
 				},
@@ -467,6 +496,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "http://www.different-test.com/test",
+					ResolvedUrl:    "http://www.different-test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     false,
 					LinkStatus:     model.LinkStatusActive,
 					HttpStatusCode: http.StatusOK,
@@ -481,6 +512,7 @@ func Test_link_details(t *testing.T) {
 					getUrl: mock.Anything,
 					res: &http.Response{
 						StatusCode: http.StatusOK,
+						Body:       http.NoBody,
 					},
 					err: nil,
 				},
@@ -505,6 +537,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "http://www.different-test.com/test",
+					ResolvedUrl:    "http://www.different-test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     false,
 					LinkStatus:     model.LinkStatusActive,
 					HttpStatusCode: http.StatusOK,
@@ -519,6 +553,7 @@ func Test_link_details(t *testing.T) {
 					getUrl: mock.Anything,
 					res: &http.Response{
 						StatusCode: http.StatusOK,
+						Body:       http.NoBody,
 					},
 					err: nil,
 				},
@@ -543,6 +578,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "http://www.different-test.com/test",
+					ResolvedUrl:    "http://www.different-test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     false,
 					LinkStatus:     model.LinkStatusInactive,
 					HttpStatusCode: http.StatusNotFound,
@@ -557,6 +594,7 @@ func Test_link_details(t *testing.T) {
 					getUrl: mock.Anything,
 					res: &http.Response{
 						StatusCode: http.StatusNotFound,
+						Body:       http.NoBody,
 					},
 					err: nil,
 				},
@@ -581,6 +619,8 @@ func Test_link_details(t *testing.T) {
 				{
 					Name:           "Go to test",
 					Url:            "http://www.different-test.com/test",
+					ResolvedUrl:    "http://www.different-test.com/test",
+					Type:           model.LinkTypeHttp,
 					IsInternal:     false,
 					LinkStatus:     model.LinkStatusInactive,
 					HttpStatusCode: -1,
@@ -603,14 +643,13 @@ func Test_link_details(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			mc := new(mc.WebClientMock)
 			for _, v := range tc.mockClient {
-				mc.On("Get", v.getUrl).Return(v.res, v.err)
+				mc.On("Do", http.MethodHead, v.getUrl).Return(v.res, v.err)
 			}
 
-			sut := &analyser{
-				client: mc,
-			}
+			sut := newLinksCheck(NewLinkChecker(mc, 0))()
 
-			actRes, actErr := sut.linksDetail(context.Background(), tc.hostUrl, []byte(tc.input))
+			analysis, actErr := runCheck(sut, tc.hostUrl, tc.input)
+			actRes := analysis.Links
 
 			if tc.expErr == nil {
 				assert.NoError(t, actErr)
@@ -622,3 +661,214 @@ func Test_link_details(t *testing.T) {
 		})
 	}
 }
+
+func Test_analyse_page_should_classify_links_against_the_final_url(t *testing.T) {
+	mc := new(mc.WebClientMock)
+	mc.On("Do", http.MethodHead, "https://www.example.com/about").
+		Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil)
+	sut := NewAnalyser(NewLinkChecker(mc, 0), nil)
+
+	analysis, err := sut.AnalysePage(context.Background(), &model.DownloadedWebpage{
+		StatusCode: http.StatusOK,
+		Url:        "http://example.com",
+		FinalUrl:   "https://www.example.com/en/",
+		Content:    []byte(`<html><body><a href="https://www.example.com/about">About</a></body></html>`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analysis.InternalLinkCount)
+	assert.Equal(t, 0, analysis.ExternalLinkCount)
+}
+
+func Test_analyse_page_should_count_links_blocked_by_robots_apart_from_inactive_links(t *testing.T) {
+	mc := new(mc.WebClientMock)
+	mc.On("Do", http.MethodHead, "https://www.example.com/private").
+		Return((*http.Response)(nil), fmt.Errorf("%w, https://www.example.com/private", BlockedByRobotsErr))
+	mc.On("Do", http.MethodHead, "https://www.example.com/missing").
+		Return(&http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil)
+	sut := NewAnalyser(NewLinkChecker(mc, 0), nil)
+
+	analysis, err := sut.AnalysePage(context.Background(), &model.DownloadedWebpage{
+		StatusCode: http.StatusOK,
+		Url:        "https://www.example.com",
+		Content: []byte(`<html><body><a href="https://www.example.com/private">Private</a>` +
+			`<a href="https://www.example.com/missing">Missing</a></body></html>`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, analysis.BlockedLinkCount)
+	assert.Equal(t, 1, analysis.InactiveLinkCount)
+	assert.Equal(t, model.LinkStatusBlocked, analysis.Links[0].LinkStatus)
+}
+
+func Test_analyse_page_should_count_the_links_by_type(t *testing.T) {
+	mc := new(mc.WebClientMock)
+	mc.On("Do", http.MethodHead, "https://www.example.com/about").
+		Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil)
+	sut := NewAnalyser(NewLinkChecker(mc, 0), nil)
+
+	analysis, err := sut.AnalysePage(context.Background(), &model.DownloadedWebpage{
+		StatusCode: http.StatusOK,
+		Url:        "https://www.example.com",
+		Content: []byte(`<html><body id="main"><a href="/about">About</a><a href="#main">Main</a>` +
+			`<a href="mailto:info@example.com">Mail</a><a href="tel:123">Call</a></body></html>`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[model.LinkType]int{
+		model.LinkTypeHttp: 1, model.LinkTypeFragment: 1, model.LinkTypeMailto: 1, model.LinkTypeTel: 1,
+	}, analysis.LinkTypeCounts)
+	assert.Equal(t, 2, analysis.ActiveLinkCount)
+	assert.Equal(t, 0, analysis.InactiveLinkCount)
+	mc.AssertNumberOfCalls(t, "Do", 1)
+}
+
+func Test_link_details_should_resolve_the_links_like_a_browser(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		documentUrl string
+		input       string
+		resolvedUrl string
+		getUrl      string
+		isInternal  bool
+	}{
+		{
+			desc:        "parent directory link",
+			documentUrl: "https://www.test.com/docs/guide/intro",
+			input:       `<a href="../api">api</a>`,
+			resolvedUrl: "https://www.test.com/docs/api",
+			getUrl:      "https://www.test.com/docs/api",
+			isInternal:  true,
+		},
+		{
+			desc:        "query only link",
+			documentUrl: "https://www.test.com/search?q=old",
+			input:       `<a href="?q=1">next</a>`,
+			resolvedUrl: "https://www.test.com/search?q=1",
+			getUrl:      "https://www.test.com/search?q=1",
+			isInternal:  true,
+		},
+		{
+			desc:        "protocol relative link",
+			documentUrl: "https://www.test.com/",
+			input:       `<a href="//cdn.test.com/lib.js">lib</a>`,
+			resolvedUrl: "https://cdn.test.com/lib.js",
+			getUrl:      "https://cdn.test.com/lib.js",
+			isInternal:  false,
+		},
+		{
+			desc:        "relative link of a document with a base href",
+			documentUrl: "https://www.test.com/en/page",
+			input:       `<head><base href="https://static.test.com/v2/"></head><a href="img/logo.png">logo</a>`,
+			resolvedUrl: "https://static.test.com/v2/img/logo.png",
+			getUrl:      "https://static.test.com/v2/img/logo.png",
+			isInternal:  false,
+		},
+		{
+			desc:        "relative base href",
+			documentUrl: "https://www.test.com/en/page",
+			input:       `<base href="/assets/"><a href="app.css">css</a>`,
+			resolvedUrl: "https://www.test.com/assets/app.css",
+			getUrl:      "https://www.test.com/assets/app.css",
+			isInternal:  true,
+		},
+		{
+			desc:        "fragment is not fetched",
+			documentUrl: "https://www.test.com/",
+			input:       `<a href="/faq#contact">faq</a>`,
+			resolvedUrl: "https://www.test.com/faq#contact",
+			getUrl:      "https://www.test.com/faq",
+			isInternal:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			mc := new(mc.WebClientMock)
+			mc.On("Do", http.MethodHead, tc.getUrl).Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil)
+			sut := newLinksCheck(NewLinkChecker(mc, 0))()
+
+			analysis, err := runCheck(sut, tc.documentUrl, tc.input)
+			links := analysis.Links
+
+			assert.NoError(t, err)
+			assert.Len(t, links, 1)
+			assert.Equal(t, tc.resolvedUrl, links[0].ResolvedUrl)
+			assert.Equal(t, tc.isInternal, links[0].IsInternal)
+			assert.Equal(t, model.LinkStatusActive, links[0].LinkStatus)
+			mc.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_link_details_should_classify_the_links_which_are_not_fetched(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		input      string
+		linkType   model.LinkType
+		linkStatus model.LinkStatus
+		isInternal bool
+	}{
+		{
+			desc:       "mailto link",
+			input:      `<a href="mailto:info@test.com">mail</a>`,
+			linkType:   model.LinkTypeMailto,
+			linkStatus: model.LinkStatusNotChecked,
+		},
+		{
+			desc:       "tel link",
+			input:      `<a href=" tel:+94112345678">call</a>`,
+			linkType:   model.LinkTypeTel,
+			linkStatus: model.LinkStatusNotChecked,
+		},
+		{
+			desc:       "javascript link",
+			input:      `<a href="JavaScript:void(0)">menu</a>`,
+			linkType:   model.LinkTypeJavascript,
+			linkStatus: model.LinkStatusNotChecked,
+		},
+		{
+			desc:       "data link",
+			input:      `<a href="data:text/plain,hello">data</a>`,
+			linkType:   model.LinkTypeOther,
+			linkStatus: model.LinkStatusNotChecked,
+		},
+		{
+			desc:       "fragment link of a named anchor",
+			input:      `<a name="intro"></a><a href="#intro">intro</a>`,
+			linkType:   model.LinkTypeFragment,
+			linkStatus: model.LinkStatusActive,
+			isInternal: true,
+		},
+		{
+			desc:       "fragment link to the top of the document",
+			input:      `<a href="#top">top</a>`,
+			linkType:   model.LinkTypeFragment,
+			linkStatus: model.LinkStatusActive,
+			isInternal: true,
+		},
+		{
+			desc:       "fragment link of a missing anchor",
+			input:      `<div id="intro"></div><a href="#missing">missing</a>`,
+			linkType:   model.LinkTypeFragment,
+			linkStatus: model.LinkStatusInactive,
+			isInternal: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			mc := new(mc.WebClientMock)
+			sut := newLinksCheck(NewLinkChecker(mc, 0))()
+
+			analysis, err := runCheck(sut, "https://www.test.com/page", tc.input)
+			links := analysis.Links
+
+			assert.NoError(t, err)
+			assert.Len(t, links, 1)
+			assert.Equal(t, tc.linkType, links[0].Type)
+			assert.Equal(t, tc.linkStatus, links[0].LinkStatus)
+			assert.Equal(t, tc.isInternal, links[0].IsInternal)
+			assert.Equal(t, 0, links[0].HttpStatusCode)
+			assert.Empty(t, mc.Calls)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package webpage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// maxElementAttrLength is the part of the attribute values shown by Token.Element.
+const maxElementAttrLength = 60
+
+// Check is a plugin of the analyser. The document is tokenized once and every check sees all the
+// tokens in document order, then it adds its findings to the analysis.
+type Check interface {
+	// Name identifies the check, it is the key of the section the check adds to Analysis.Sections.
+	Name() string
+	// Token is called for every token of the document.
+	Token(t *Token)
+	// Finish is called once the whole document is tokenized, an error doesn't fail the analysis.
+	Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error
+}
+
+// NewCheck creates the check of a single document, a check keeps the state of the document it sees.
+type NewCheck func() Check
+
+// Token is a token of the document, Line is the line of the document the token starts on.
+type Token struct {
+	html.Token
+	Line int
+}
+
+// AttrValue returns the value of the attribute key, the keys of the attributes are lower case.
+func (t *Token) AttrValue(key string) (string, bool) {
+	for _, a := range t.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// IsStartTag reports whether the token opens an element, self closing elements included.
+func (t *Token) IsStartTag() bool {
+	return t.Type == html.StartTagToken || t.Type == html.SelfClosingTagToken
+}
+
+// Element describes the start tag for the issues located on it, with the attributes which
+// identify the element, like `<img id="logo" src="logo.png">`.
+func (t *Token) Element() string {
+	b := strings.Builder{}
+	b.WriteString("<" + t.Data)
+	for _, key := range []string{"id", "name", "type", "href", "src", "for"} {
+		if v, ok := t.AttrValue(key); ok {
+			if len(v) > maxElementAttrLength {
+				// cut at a rune boundary, the value must stay valid UTF-8
+				end := maxElementAttrLength
+				for end > 0 && !utf8.RuneStart(v[end]) {
+					end--
+				}
+				v = v[:end] + "..."
+			}
+			b.WriteString(fmt.Sprintf(" %s=%q", key, v))
+		}
+	}
+	b.WriteString(">")
+
+	return b.String()
+}
+
+// Document is the page being analysed.
+type Document struct {
+	Page *model.DownloadedWebpage
+	// Url is the url the page was served from, after the redirects. BaseUrl is the url the relative
+	// references of the document resolve against, the <base href> when the document has one.
+	Url     *url.URL
+	BaseUrl *url.URL
+}
+
+func newDocument(page *model.DownloadedWebpage) (*Document, error) {
+	// links are relative to the page the client ended up on, not the requested one
+	documentUrl := page.FinalUrl
+	if documentUrl == "" {
+		documentUrl = page.Url
+	}
+
+	u, err := url.Parse(documentUrl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the document url %q, %v", documentUrl, err)
+	}
+
+	return &Document{
+		Page:    page,
+		Url:     u,
+		BaseUrl: u,
+	}, nil
+}
+
+// Resolve returns the absolute url of a reference of the document, the surrounding white space is
+// trimmed as browsers do.
+func (d *Document) Resolve(ref string) (*url.URL, error) {
+	return d.BaseUrl.Parse(strings.TrimSpace(ref))
+}
+
+// tokenize reads the document once and hands every token to the checks, the first <base href> of
+// the document sets its BaseUrl.
+func tokenize(doc *Document, checks []Check) error {
+	baseHref := ""
+	line := 1
+	tt := html.NewTokenizer(bytes.NewReader(doc.Page.Content))
+	for {
+		if tt.Next() == html.ErrorToken {
+			if err := tt.Err(); !errors.Is(err, io.EOF) {
+				return fmt.Errorf("unable to process the document, %v", err)
+			}
+			break
+		}
+
+		t := &Token{Token: tt.Token(), Line: line}
+		line += bytes.Count(tt.Raw(), []byte("\n"))
+
+		if t.IsStartTag() && t.Data == "base" && baseHref == "" {
+			baseHref, _ = t.AttrValue("href")
+		}
+
+		for _, c := range checks {
+			c.Token(t)
+		}
+	}
+
+	if baseHref != "" {
+		if u, err := doc.Url.Parse(strings.TrimSpace(baseHref)); err == nil {
+			doc.BaseUrl = u
+		} else {
+			logrus.Warnf("ignoring the invalid base href %q, %v", baseHref, err)
+		}
+	}
+
+	return nil
+}
+
+// issueList collects the findings of a check.
+type issueList struct {
+	list []*model.Issue
+}
+
+func (i *issueList) add(code string, severity model.Severity, line int, format string, args ...any) {
+	i.list = append(i.list, &model.Issue{
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     line,
+	})
+}
+
+// addAt adds an issue located on the element the token starts.
+func (i *issueList) addAt(code string, severity model.Severity, t *Token, format string, args ...any) {
+	i.list = append(i.list, &model.Issue{
+		Code:     code,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     t.Line,
+		Element:  t.Element(),
+	})
+}
+
+func (i *issueList) count(severity model.Severity) int {
+	n := 0
+	for _, issue := range i.list {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+
+	return n
+}
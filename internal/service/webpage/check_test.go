@@ -0,0 +1,100 @@
+package webpage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	mc "github.com/DiLRandI/web-analyser/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+)
+
+// imagesCheck is an extra check listing the images of the page with their lines.
+type imagesCheck struct {
+	images map[string]int
+}
+
+func (c *imagesCheck) Name() string {
+	return "images"
+}
+
+func (c *imagesCheck) Token(t *Token) {
+	if src, ok := t.AttrValue("src"); ok && t.IsStartTag() && t.Data == "img" {
+		c.images[src] = t.Line
+	}
+}
+
+func (c *imagesCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	analysis.Sections[c.Name()] = c.images
+
+	return nil
+}
+
+func Test_analyser_should_run_the_extra_checks_in_the_same_pass(t *testing.T) {
+	sut := NewAnalyser(NewLinkChecker(new(mc.WebClientMock), 0), nil, func() Check {
+		return &imagesCheck{images: map[string]int{}}
+	})
+
+	analysis, err := sut.AnalysePage(context.Background(), &model.DownloadedWebpage{
+		Url:     "https://www.test.com",
+		Content: []byte("<!DOCTYPE html>\n<html>\n<head><title>Images</title></head>\n<body>\n<img src=\"a.png\">\n</body>"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Images", analysis.Title)
+	assert.Equal(t, "HTML5", analysis.PageVersion)
+	assert.Equal(t, map[string]int{"a.png": 5}, analysis.Sections["images"])
+}
+
+func Test_tokenize_should_resolve_against_the_first_base_href(t *testing.T) {
+	doc, err := newDocument(&model.DownloadedWebpage{
+		Url:      "https://www.test.com",
+		FinalUrl: "https://www.test.com/en/",
+		Content:  []byte(`<base href="/static/"><base href="/other/"><p>text</p>`),
+	})
+	require.NoError(t, err)
+
+	tokens := 0
+	require.NoError(t, tokenize(doc, []Check{&countingCheck{count: &tokens}}))
+
+	assert.Equal(t, "https://www.test.com/en/", doc.Url.String())
+	assert.Equal(t, "https://www.test.com/static/", doc.BaseUrl.String())
+	resolved, err := doc.Resolve(" img/logo.png ")
+	require.NoError(t, err)
+	assert.Equal(t, "https://www.test.com/static/img/logo.png", resolved.String())
+	assert.Equal(t, 5, tokens)
+}
+
+type countingCheck struct {
+	count *int
+}
+
+func (c *countingCheck) Name() string {
+	return "counting"
+}
+
+func (c *countingCheck) Token(t *Token) {
+	if t.Type != html.ErrorToken {
+		*c.count++
+	}
+}
+
+func (c *countingCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	return nil
+}
+
+func Test_token_element_should_cut_a_long_value_at_a_rune_boundary(t *testing.T) {
+	// the 60th byte is in the middle of a two bytes rune
+	sut := &Token{Token: html.Token{Type: html.StartTagToken, Data: "a", Attr: []html.Attribute{
+		{Key: "href", Val: "/" + strings.Repeat("é", 40)},
+	}}}
+
+	element := sut.Element()
+
+	assert.True(t, utf8.ValidString(element))
+	assert.Equal(t, `<a href="/`+strings.Repeat("é", 29)+`...">`, element)
+}
@@ -0,0 +1,234 @@
+package webpage
+
+import (
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+)
+
+// quirksPublicIds are the public identifiers of the legacy doctypes which put the browsers in quirks
+// mode, following https://html.spec.whatwg.org/multipage/parsing.html#the-initial-insertion-mode.
+var quirksPublicIds = []string{
+	"-//w3o//dtd w3 html strict 3.0//en//",
+	"-/w3c/dtd html 4.0 transitional/en",
+	"html",
+}
+
+// quirksPublicIdPrefixes are the prefixes of the quirks mode public identifiers.
+var quirksPublicIdPrefixes = []string{
+	"+//silmaril//dtd html pro v0r11 19970101//",
+	"-//as//dtd html 3.0 aswedit + extensions//",
+	"-//advasoft ltd//dtd html 3.0 aswedit + extensions//",
+	"-//ietf//dtd html 2.0 level 1//",
+	"-//ietf//dtd html 2.0 level 2//",
+	"-//ietf//dtd html 2.0 strict level 1//",
+	"-//ietf//dtd html 2.0 strict level 2//",
+	"-//ietf//dtd html 2.0 strict//",
+	"-//ietf//dtd html 2.0//",
+	"-//ietf//dtd html 2.1e//",
+	"-//ietf//dtd html 3.0//",
+	"-//ietf//dtd html 3.2 final//",
+	"-//ietf//dtd html 3.2//",
+	"-//ietf//dtd html 3//",
+	"-//ietf//dtd html level 0//",
+	"-//ietf//dtd html level 1//",
+	"-//ietf//dtd html level 2//",
+	"-//ietf//dtd html level 3//",
+	"-//ietf//dtd html strict level 0//",
+	"-//ietf//dtd html strict level 1//",
+	"-//ietf//dtd html strict level 2//",
+	"-//ietf//dtd html strict level 3//",
+	"-//ietf//dtd html strict//",
+	"-//ietf//dtd html//",
+	"-//metrius//dtd metrius presentational//",
+	"-//microsoft//dtd internet explorer 2.0 html strict//",
+	"-//microsoft//dtd internet explorer 2.0 html//",
+	"-//microsoft//dtd internet explorer 2.0 tables//",
+	"-//microsoft//dtd internet explorer 3.0 html strict//",
+	"-//microsoft//dtd internet explorer 3.0 html//",
+	"-//microsoft//dtd internet explorer 3.0 tables//",
+	"-//netscape comm. corp.//dtd html//",
+	"-//netscape comm. corp.//dtd strict html//",
+	"-//o'reilly and associates//dtd html 2.0//",
+	"-//o'reilly and associates//dtd html extended 1.0//",
+	"-//o'reilly and associates//dtd html extended relaxed 1.0//",
+	"-//sq//dtd html 2.0 hotmetal + extensions//",
+	"-//softquad software//dtd hotmetal pro 6.0::19990601::extensions to html 4.0//",
+	"-//softquad//dtd hotmetal pro 4.0::19971010::extensions to html 4.0//",
+	"-//spyglass//dtd html 2.0 extended//",
+	"-//sun microsystems corp.//dtd hotjava html//",
+	"-//sun microsystems corp.//dtd hotjava strict html//",
+	"-//w3c//dtd html 3 1995-03-24//",
+	"-//w3c//dtd html 3.2 draft//",
+	"-//w3c//dtd html 3.2 final//",
+	"-//w3c//dtd html 3.2//",
+	"-//w3c//dtd html 3.2s draft//",
+	"-//w3c//dtd html 4.0 frameset//",
+	"-//w3c//dtd html 4.0 transitional//",
+	"-//w3c//dtd html experimental 19960712//",
+	"-//w3c//dtd html experimental 970421//",
+	"-//w3c//dtd w3 html//",
+	"-//w3o//dtd w3 html 3.0//",
+	"-//webtechs//dtd mozilla html 2.0//",
+	"-//webtechs//dtd mozilla html//",
+}
+
+// html401TransitionalPrefixes are quirks mode without a system identifier, almost standards mode with one.
+var html401TransitionalPrefixes = []string{
+	"-//w3c//dtd html 4.01 frameset//",
+	"-//w3c//dtd html 4.01 transitional//",
+}
+
+// almostStandardsPrefixes are the public identifiers of the almost standards mode.
+var almostStandardsPrefixes = []string{
+	"-//w3c//dtd xhtml 1.0 frameset//",
+	"-//w3c//dtd xhtml 1.0 transitional//",
+}
+
+// doctypeNames are the normalized names of the doctypes, by the description of their public identifier.
+var doctypeNames = map[string]string{
+	"html 4.01":                 "HTML 4.01 Strict",
+	"html 4.01 transitional":    "HTML 4.01 Transitional",
+	"html 4.01 frameset":        "HTML 4.01 Frameset",
+	"xhtml 1.0 strict":          "XHTML 1.0 Strict",
+	"xhtml 1.0 transitional":    "XHTML 1.0 Transitional",
+	"xhtml 1.0 frameset":        "XHTML 1.0 Frameset",
+	"xhtml 1.1":                 "XHTML 1.1",
+	"xhtml basic 1.1":           "XHTML Basic 1.1",
+	"xhtml 1.1 plus mathml 2.0": "XHTML 1.1 plus MathML 2.0",
+}
+
+// parseDoctype reads the contents of a doctype token, `html PUBLIC "public id" "system id"`, like
+// the browsers do. hasSystemId tells an empty system identifier from a missing one, forceQuirks is set
+// for the malformed doctypes, browsers render them in quirks mode.
+func parseDoctype(data string) (d *model.Doctype, hasSystemId, forceQuirks bool) {
+	d = &model.Doctype{}
+	rest := strings.TrimLeft(data, " \t\n\f\r")
+	if rest == "" {
+		return d, false, true
+	}
+
+	i := strings.IndexAny(rest, " \t\n\f\r")
+	if i < 0 {
+		i = len(rest)
+	}
+	d.Root, rest = strings.ToLower(rest[:i]), strings.TrimLeft(rest[i:], " \t\n\f\r")
+	if rest == "" {
+		return d, false, false
+	}
+
+	if len(rest) < 6 {
+		return d, false, true
+	}
+	keyword := strings.ToLower(rest[:6])
+	rest = strings.TrimLeft(rest[6:], " \t\n\f\r")
+
+	var ok bool
+	switch keyword {
+	case "public":
+		if d.PublicId, rest, ok = quotedId(rest); !ok {
+			return d, false, true
+		}
+		if rest = strings.TrimLeft(rest, " \t\n\f\r"); rest == "" {
+			return d, false, false
+		}
+		// the system identifier is optional after the public one
+		if d.SystemId, _, ok = quotedId(rest); !ok {
+			return d, false, true
+		}
+	case "system":
+		if d.SystemId, _, ok = quotedId(rest); !ok {
+			return d, false, true
+		}
+	default:
+		return d, false, true
+	}
+
+	return d, true, false
+}
+
+// quotedId reads an identifier quoted with " or ', an identifier without its closing quote is cut by a
+// `>` and is malformed.
+func quotedId(s string) (id, rest string, ok bool) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return "", s, false
+	}
+
+	end := strings.IndexByte(s[1:], s[0])
+	if end < 0 {
+		return s[1:], "", false
+	}
+
+	return s[1 : end+1], s[end+2:], true
+}
+
+// renderingMode returns the mode browsers render a document with the doctype in.
+func renderingMode(d *model.Doctype, hasSystemId, forceQuirks bool) model.RenderingMode {
+	publicId := strings.ToLower(d.PublicId)
+	systemId := strings.ToLower(d.SystemId)
+	if forceQuirks || d.Root != "html" ||
+		systemId == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" {
+		return model.RenderingModeQuirks
+	}
+
+	for _, id := range quirksPublicIds {
+		if publicId == id {
+			return model.RenderingModeQuirks
+		}
+	}
+	if hasAnyPrefix(publicId, quirksPublicIdPrefixes) {
+		return model.RenderingModeQuirks
+	}
+
+	if hasAnyPrefix(publicId, html401TransitionalPrefixes) {
+		if !hasSystemId {
+			return model.RenderingModeQuirks
+		}
+		return model.RenderingModeAlmostStandards
+	}
+	if hasAnyPrefix(publicId, almostStandardsPrefixes) {
+		return model.RenderingModeAlmostStandards
+	}
+
+	return model.RenderingModeStandards
+}
+
+// doctypeName returns the normalized name of the doctype, the description of an unknown public
+// identifier like `HTML 3.2 Final` or unknown.
+func doctypeName(d *model.Doctype, hasSystemId bool) string {
+	if d.PublicId == "" {
+		if d.Root == "html" && (!hasSystemId || strings.EqualFold(d.SystemId, "about:legacy-compat")) {
+			return "HTML5"
+		}
+		return "unknown"
+	}
+
+	// the public identifiers are `-//owner//DTD description//language`, + for the registered owners
+	parts := strings.Split(d.PublicId, "//")
+	if len(parts) < 3 || (parts[0] != "-" && parts[0] != "+") {
+		return "unknown"
+	}
+	description := strings.TrimSpace(parts[2])
+	if len(description) > 4 && strings.EqualFold(description[:4], "dtd ") {
+		description = strings.TrimSpace(description[4:])
+	}
+
+	if name, ok := doctypeNames[strings.ToLower(description)]; ok {
+		return name
+	}
+	if description == "" {
+		return "unknown"
+	}
+
+	return description
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_doctype_check_should_classify_the_doctype(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		input   string
+		doctype *model.Doctype
+	}{
+		{
+			desc:  "html5",
+			input: `<!-- generated --><!doctype html><html></html>`,
+			doctype: &model.Doctype{Name: "HTML5", Root: "html",
+				RenderingMode: model.RenderingModeStandards},
+		},
+		{
+			desc:  "html5 legacy compat",
+			input: `<!DOCTYPE html SYSTEM "about:legacy-compat">`,
+			doctype: &model.Doctype{Name: "HTML5", Root: "html", SystemId: "about:legacy-compat",
+				RenderingMode: model.RenderingModeStandards},
+		},
+		{
+			desc:  "html 4.01 transitional with a system identifier",
+			input: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`,
+			doctype: &model.Doctype{Name: "HTML 4.01 Transitional", Root: "html",
+				PublicId: "-//W3C//DTD HTML 4.01 Transitional//EN", SystemId: "http://www.w3.org/TR/html4/loose.dtd",
+				RenderingMode: model.RenderingModeAlmostStandards},
+		},
+		{
+			desc:  "html 4.01 frameset without a system identifier",
+			input: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN">`,
+			doctype: &model.Doctype{Name: "HTML 4.01 Frameset", Root: "html",
+				PublicId: "-//W3C//DTD HTML 4.01 Frameset//EN", RenderingMode: model.RenderingModeQuirks},
+		},
+		{
+			desc:  "html 4.01 transitional with an empty system identifier",
+			input: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "">`,
+			doctype: &model.Doctype{Name: "HTML 4.01 Transitional", Root: "html",
+				PublicId: "-//W3C//DTD HTML 4.01 Transitional//EN", RenderingMode: model.RenderingModeAlmostStandards},
+		},
+		{
+			desc:    "html with an empty system identifier",
+			input:   `<!DOCTYPE html SYSTEM "">`,
+			doctype: &model.Doctype{Name: "unknown", Root: "html", RenderingMode: model.RenderingModeStandards},
+		},
+		{
+			desc: "xhtml 1.0 transitional",
+			input: `<!DOCTYPE html PUBLIC '-//W3C//DTD XHTML 1.0 Transitional//EN'
+				'http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd'>`,
+			doctype: &model.Doctype{Name: "XHTML 1.0 Transitional", Root: "html",
+				PublicId:      "-//W3C//DTD XHTML 1.0 Transitional//EN",
+				SystemId:      "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd",
+				RenderingMode: model.RenderingModeAlmostStandards},
+		},
+		{
+			desc: "xhtml 1.1",
+			input: `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN"
+				"http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd">`,
+			doctype: &model.Doctype{Name: "XHTML 1.1", Root: "html", PublicId: "-//W3C//DTD XHTML 1.1//EN",
+				SystemId: "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd", RenderingMode: model.RenderingModeStandards},
+		},
+		{
+			desc:  "legacy html 3.2",
+			input: `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`,
+			doctype: &model.Doctype{Name: "HTML 3.2 Final", Root: "html",
+				PublicId: "-//W3C//DTD HTML 3.2 Final//EN", RenderingMode: model.RenderingModeQuirks},
+		},
+		{
+			desc:    "another root element",
+			input:   `<!DOCTYPE svg>`,
+			doctype: &model.Doctype{Name: "unknown", Root: "svg", RenderingMode: model.RenderingModeQuirks},
+		},
+		{
+			desc:    "a malformed doctype",
+			input:   `<!DOCTYPE html PUBLIC -//W3C//DTD HTML 4.01//EN>`,
+			doctype: &model.Doctype{Name: "HTML5", Root: "html", RenderingMode: model.RenderingModeQuirks},
+		},
+		{
+			desc:    "a missing doctype",
+			input:   `<html></html>`,
+			doctype: &model.Doctype{Name: "none", RenderingMode: model.RenderingModeQuirks},
+		},
+		{
+			desc:    "a doctype after the content",
+			input:   `<p>hello</p><!DOCTYPE html>`,
+			doctype: &model.Doctype{Name: "none", RenderingMode: model.RenderingModeQuirks},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			analysis, err := runCheck(newVersionCheck(), "http://www.test.com", tc.input)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.doctype, analysis.Sections["doctype"])
+			assert.Equal(t, tc.doctype.Name, analysis.PageVersion)
+		})
+	}
+}
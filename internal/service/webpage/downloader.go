@@ -2,6 +2,7 @@ package webpage
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,9 +26,9 @@ func NewDownloader(client WebClient) Downloader {
 }
 
 func (s *downloader) Download(ctx context.Context, url string) (*model.DownloadedWebpage, error) {
-	res, err := s.client.Get(url)
+	res, err := s.client.Get(ctx, url)
 	if err != nil {
-		return nil, fmt.Errorf("unable to download the webpage, %v", err)
+		return nil, fmt.Errorf("unable to download the webpage, %w", err)
 	}
 	defer func() {
 		if err := res.Body.Close(); err != nil {
@@ -35,19 +36,84 @@ func (s *downloader) Download(ctx context.Context, url string) (*model.Downloade
 		}
 	}()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("the requested page failed with status %s", res.Status)
-	}
-
+	// error pages are analysed too, the status code is part of the result
 	content, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read the body content, %v", err)
 	}
 
+	// the analysis reads utf-8, the pages in the other encodings are transcoded
+	content, encoding := decodeContent(res.Header.Get("Content-Type"), content)
+
+	finalUrl := url
+	if res.Request != nil && res.Request.URL != nil {
+		finalUrl = res.Request.URL.String()
+	}
+
 	return &model.DownloadedWebpage{
 		StatusCode: res.StatusCode,
 		Status:     res.Status,
 		Url:        url,
+		FinalUrl:   finalUrl,
+		Redirects:  redirectChain(res),
+		Headers:    res.Header,
+		TLS:        connectionState(res.TLS),
+		Encoding:   encoding,
 		Content:    content,
 	}, nil
 }
+
+// connectionState describes the TLS connection, the certificate is the leaf certificate of the server.
+func connectionState(state *tls.ConnectionState) *model.TLS {
+	if state == nil {
+		return nil
+	}
+
+	t := &model.TLS{
+		Version:     tlsVersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+	}
+	if len(state.PeerCertificates) > 0 {
+		cert := state.PeerCertificates[0]
+		t.CertificateSubject = cert.Subject.String()
+		t.CertificateIssuer = cert.Issuer.String()
+		t.CertificateExpires = cert.NotAfter
+	}
+
+	return t
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04X", version)
+	}
+}
+
+// redirectChain rebuilds the redirects followed by the client, http.Client links every request
+// it creates for a redirect to the response which caused it.
+func redirectChain(res *http.Response) []*model.Redirect {
+	var hops []*model.Redirect
+	for req := res.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		r := req.Response
+		hop := &model.Redirect{
+			StatusCode: r.StatusCode,
+			Location:   r.Header.Get("Location"),
+		}
+		if r.Request != nil && r.Request.URL != nil {
+			hop.Url = r.Request.URL.String()
+		}
+
+		hops = append([]*model.Redirect{hop}, hops...)
+	}
+
+	return hops
+}
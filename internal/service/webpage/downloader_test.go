@@ -5,8 +5,10 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
 	mc "github.com/DiLRandI/web-analyser/mock"
@@ -31,14 +33,23 @@ func Test_download(t *testing.T) {
 			mcErr: errors.New("test failure"),
 		},
 		{
-			desc: "Download should return an error if status is not 200 ok",
+			desc: "Download should return the error page if status is not 200 ok",
 			url:  "http://test.com",
-			err:  errors.New("the requested page failed with status 404 NOT FOUND"),
-			res:  nil,
+			err:  nil,
+			res: &model.DownloadedWebpage{
+				StatusCode: http.StatusNotFound,
+				Status:     "404 NOT FOUND",
+				Url:        "http://test.com",
+				FinalUrl:   "http://test.com",
+				Headers:    http.Header{"Content-Type": {"text/html"}},
+				Encoding:   &model.Encoding{Used: "utf-8"},
+				Content:    []byte("not found"),
+			},
 			mcRes: &http.Response{
 				StatusCode: http.StatusNotFound,
 				Status:     "404 NOT FOUND",
-				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     http.Header{"Content-Type": {"text/html"}},
+				Body:       io.NopCloser(strings.NewReader("not found")),
 			},
 			mcErr: nil,
 		},
@@ -50,6 +61,8 @@ func Test_download(t *testing.T) {
 				StatusCode: http.StatusOK,
 				Status:     "200 OK",
 				Url:        "http://test.com",
+				FinalUrl:   "http://test.com",
+				Encoding:   &model.Encoding{Used: "utf-8"},
 				Content:    []byte("test content"),
 			},
 			mcRes: &http.Response{
@@ -81,3 +94,32 @@ func Test_download(t *testing.T) {
 		})
 	}
 }
+
+func Test_download_should_record_the_redirect_chain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/en", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/en", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/en/", http.StatusFound)
+	})
+	mux.HandleFunc("/en/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("final"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{Timeout: time.Second})
+	assert.NoError(t, err)
+	sut := NewDownloader(client)
+	res, err := sut.Download(context.Background(), srv.URL+"/")
+
+	assert.NoError(t, err)
+	assert.Equal(t, srv.URL+"/", res.Url)
+	assert.Equal(t, srv.URL+"/en/", res.FinalUrl)
+	assert.Equal(t, []*model.Redirect{
+		{Url: srv.URL + "/", StatusCode: http.StatusMovedPermanently, Location: "/en"},
+		{Url: srv.URL + "/en", StatusCode: http.StatusFound, Location: "/en/"},
+	}, res.Redirects)
+	assert.Equal(t, []byte("final"), res.Content)
+}
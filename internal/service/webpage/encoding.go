@@ -0,0 +1,177 @@
+package webpage
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// metaPrescanLength is the part of the document browsers look for a <meta> charset in.
+	metaPrescanLength = 1024
+
+	encodingUtf8 = "utf-8"
+	// encodingDefault is the encoding browsers fall back to for the documents which are not utf-8.
+	encodingDefault = "windows-1252"
+)
+
+var byteOrderMarks = []struct {
+	bom      []byte
+	encoding string
+}{
+	{[]byte{0xEF, 0xBB, 0xBF}, encodingUtf8},
+	{[]byte{0xFE, 0xFF}, "utf-16be"},
+	{[]byte{0xFF, 0xFE}, "utf-16le"},
+}
+
+// decodeContent detects the encoding of the document and returns its content transcoded to utf-8.
+// Documents which are not text are returned as they are, with a nil encoding.
+func decodeContent(contentType string, content []byte) ([]byte, *model.Encoding) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if contentType != "" && err == nil && !isTextMediaType(mediaType) {
+		return content, nil
+	}
+
+	enc := &model.Encoding{HeaderCharset: encodingName(params["charset"])}
+	for _, b := range byteOrderMarks {
+		if bytes.HasPrefix(content, b.bom) {
+			enc.Bom = b.encoding
+			content = content[len(b.bom):]
+			break
+		}
+	}
+
+	enc.MetaCharset = metaCharset(content)
+	enc.Declared = enc.HeaderCharset
+	if enc.Declared == "" {
+		enc.Declared = enc.MetaCharset
+	}
+	enc.Detected = detectEncoding(content, enc)
+
+	for _, e := range []string{enc.Bom, enc.HeaderCharset, enc.MetaCharset, enc.Detected, encodingUtf8} {
+		if e != "" {
+			enc.Used = e
+			break
+		}
+	}
+
+	known := ""
+	for _, e := range []string{enc.Bom, enc.HeaderCharset, enc.MetaCharset, enc.Detected} {
+		if e == "" {
+			continue
+		}
+		if known != "" && e != known {
+			enc.Mismatch = true
+		}
+		known = e
+	}
+
+	if enc.Used == encodingUtf8 {
+		return content, enc
+	}
+
+	e, _ := charset.Lookup(enc.Used)
+	if e == nil {
+		// the declared charset is not a known encoding
+		return content, enc
+	}
+	decoded, err := e.NewDecoder().Bytes(content)
+	if err != nil {
+		logrus.Warnf("unable to decode the document from %s, %v", enc.Used, err)
+		return content, enc
+	}
+
+	return decoded, enc
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || strings.Contains(mediaType, "html") ||
+		strings.Contains(mediaType, "xml")
+}
+
+// encodingName returns the canonical name of an encoding label, like shift_jis for Shift-JIS, the
+// unknown labels are kept lower case.
+func encodingName(label string) string {
+	label = strings.ToLower(strings.Trim(strings.TrimSpace(label), `"'`))
+	if label == "" {
+		return ""
+	}
+
+	if _, name := charset.Lookup(label); name != "" {
+		return name
+	}
+
+	return label
+}
+
+// metaCharset returns the charset of the <meta charset> or <meta http-equiv="Content-Type"> at the
+// start of the document, a utf-16 declaration means utf-8 as the document couldn't be read otherwise.
+func metaCharset(content []byte) string {
+	if len(content) > metaPrescanLength {
+		content = content[:metaPrescanLength]
+	}
+
+	tt := html.NewTokenizer(bytes.NewReader(content))
+	for tt.Next() != html.ErrorToken {
+		t := &Token{Token: tt.Token()}
+		if !t.IsStartTag() || t.Data != "meta" {
+			continue
+		}
+
+		label, ok := t.AttrValue("charset")
+		if !ok {
+			equiv, _ := t.AttrValue("http-equiv")
+			if !strings.EqualFold(strings.TrimSpace(equiv), "content-type") {
+				continue
+			}
+			value, _ := t.AttrValue("content")
+			if _, params, err := mime.ParseMediaType(value); err == nil {
+				label = params["charset"]
+			}
+		}
+
+		if name := encodingName(label); name != "" {
+			if strings.HasPrefix(name, "utf-16") {
+				return encodingUtf8
+			}
+			return name
+		}
+	}
+
+	return ""
+}
+
+// detectEncoding returns the encoding the bytes of the document are valid in. Valid utf-8 with non
+// ASCII characters is utf-8, otherwise the declared encoding when the document decodes without errors
+// in it and the browsers default when it doesn't.
+func detectEncoding(content []byte, enc *model.Encoding) string {
+	if enc.Bom != "" {
+		return enc.Bom
+	}
+
+	if utf8.Valid(content) {
+		for _, b := range content {
+			if b >= utf8.RuneSelf {
+				return encodingUtf8
+			}
+		}
+		return ""
+	}
+
+	if enc.Declared != "" && enc.Declared != encodingUtf8 {
+		if e, _ := charset.Lookup(enc.Declared); e != nil {
+			decoded, err := e.NewDecoder().Bytes(content)
+			if err == nil && !bytes.ContainsRune(decoded, utf8.RuneError) {
+				return enc.Declared
+			}
+		}
+	}
+
+	return encodingDefault
+}
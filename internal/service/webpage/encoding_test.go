@@ -0,0 +1,125 @@
+package webpage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	mc "github.com/DiLRandI/web-analyser/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html/charset"
+)
+
+func encode(t *testing.T, name, text string) []byte {
+	e, _ := charset.Lookup(name)
+	require.NotNil(t, e)
+	b, err := e.NewEncoder().Bytes([]byte(text))
+	require.NoError(t, err)
+
+	return b
+}
+
+func Test_decode_content_should_transcode_to_utf8(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		contentType string
+		content     func(t *testing.T) []byte
+		encoding    *model.Encoding
+		title       string
+	}{
+		{
+			desc:        "the charset of the header",
+			contentType: "text/html; charset=Shift_JIS",
+			content: func(t *testing.T) []byte {
+				return encode(t, "shift_jis", "<title>日本語のページ</title>")
+			},
+			encoding: &model.Encoding{Declared: "shift_jis", HeaderCharset: "shift_jis", Detected: "shift_jis",
+				Used: "shift_jis"},
+			title: "日本語のページ",
+		},
+		{
+			desc:        "the charset of the meta tag",
+			contentType: "text/html",
+			content: func(t *testing.T) []byte {
+				return encode(t, "windows-1251", `<meta charset="windows-1251"><title>Русская страница</title>`)
+			},
+			encoding: &model.Encoding{Declared: "windows-1251", MetaCharset: "windows-1251", Detected: "windows-1251",
+				Used: "windows-1251"},
+			title: "Русская страница",
+		},
+		{
+			desc:        "the charset of the http-equiv meta tag",
+			contentType: "",
+			content: func(t *testing.T) []byte {
+				return encode(t, "iso-8859-2", `<meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-2">`+
+					`<title>Strona główna</title>`)
+			},
+			encoding: &model.Encoding{Declared: "iso-8859-2", MetaCharset: "iso-8859-2", Detected: "iso-8859-2",
+				Used: "iso-8859-2"},
+			title: "Strona główna",
+		},
+		{
+			desc:        "the byte order mark wins over the declarations",
+			contentType: "text/html; charset=iso-8859-1",
+			content: func(t *testing.T) []byte {
+				return append([]byte{0xFF, 0xFE}, encode(t, "utf-16le", "<title>Ünïcode</title>")...)
+			},
+			encoding: &model.Encoding{Declared: "windows-1252", HeaderCharset: "windows-1252", Bom: "utf-16le",
+				Detected: "utf-16le", Used: "utf-16le", Mismatch: true},
+			title: "Ünïcode",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			content, encoding := decodeContent(tc.contentType, tc.content(t))
+
+			assert.Equal(t, tc.encoding, encoding)
+			analysis, err := runCheck(newTitleCheck(), "http://www.test.com", `<html><head>`+string(content)+`</head></html>`)
+			require.NoError(t, err)
+			assert.Equal(t, tc.title, analysis.Title)
+		})
+	}
+}
+
+func Test_decode_content_should_flag_the_mismatches(t *testing.T) {
+	content, encoding := decodeContent("text/html; charset=iso-8859-1", []byte(`<title>Café</title>`))
+
+	assert.Equal(t, &model.Encoding{Declared: "windows-1252", HeaderCharset: "windows-1252", Detected: "utf-8",
+		Used: "windows-1252", Mismatch: true}, encoding)
+	// browsers follow the header, the analysis shows the page the way they do
+	assert.Equal(t, "<title>CafÃ©</title>", string(content))
+
+	_, encoding = decodeContent("text/html; charset=utf-8", encode(t, "windows-1252", `<title>Café</title>`))
+	assert.Equal(t, "windows-1252", encoding.Detected)
+	assert.True(t, encoding.Mismatch)
+}
+
+func Test_decode_content_should_ignore_the_other_media_types(t *testing.T) {
+	content, encoding := decodeContent("image/png", []byte{0x89, 0x50, 0x4E, 0x47})
+
+	assert.Nil(t, encoding)
+	assert.Equal(t, []byte{0x89, 0x50, 0x4E, 0x47}, content)
+}
+
+func Test_download_should_transcode_the_page(t *testing.T) {
+	content := encode(t, "windows-1251", "<html><head><title>Привет</title></head></html>")
+	client := new(mc.WebClientMock)
+	client.On("Get", "http://test.com").Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/html; charset=windows-1251"}},
+		Body:       io.NopCloser(bytes.NewReader(content)),
+	}, nil)
+
+	page, err := NewDownloader(client).Download(context.Background(), "http://test.com")
+	require.NoError(t, err)
+
+	analysis, err := NewAnalyser(NewLinkChecker(client, 0), nil).AnalysePage(context.Background(), page)
+	require.NoError(t, err)
+	assert.Equal(t, "Привет", analysis.Title)
+	assert.Equal(t, page.Encoding, analysis.Sections["encoding"])
+}
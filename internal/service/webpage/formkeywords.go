@@ -0,0 +1,82 @@
+package webpage
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+)
+
+// FormKeywords are the words identifying the forms of a class, by form class. The keywords are matched
+// as whole words against the text of the form, ignoring the case and the punctuation.
+type FormKeywords map[string][]string
+
+// DefaultFormKeywords returns the keywords of the form classes in English, German, French, Spanish,
+// Italian, Portuguese, Dutch, Polish, Russian, Japanese and Chinese.
+func DefaultFormKeywords() FormKeywords {
+	return FormKeywords{
+		model.FormClassLogin: {
+			"login", "log in", "signin", "sign in", "log on", "anmelden", "einloggen", "connexion",
+			"se connecter", "s identifier", "iniciar sesión", "ingresar", "entrar", "acceder", "accedi",
+			"iniciar sessão", "fazer login", "inloggen", "zaloguj", "zaloguj się", "войти", "вход",
+			"ログイン", "登录", "登入",
+		},
+		model.FormClassSignup: {
+			"signup", "sign up", "register", "registration", "create account", "create an account",
+			"registrieren", "registrierung", "konto erstellen", "s inscrire", "inscription",
+			"créer un compte", "registrarse", "regístrate", "crear cuenta", "registrati", "registrazione",
+			"crea account", "cadastrar", "cadastre se", "criar conta", "registreren", "account aanmaken",
+			"zarejestruj", "zarejestruj się", "регистрация", "зарегистрироваться", "新規登録", "注册",
+		},
+		model.FormClassSearch: {
+			"search", "suche", "suchen", "rechercher", "recherche", "buscar", "búsqueda", "cerca",
+			"pesquisar", "zoeken", "szukaj", "поиск", "найти", "検索", "搜索",
+		},
+		model.FormClassPasswordReset: {
+			"forgot password", "forgot your password", "reset password", "reset your password",
+			"recover password", "password reset", "passwort vergessen", "passwort zurücksetzen",
+			"mot de passe oublié", "réinitialiser le mot de passe", "olvidé mi contraseña",
+			"restablecer contraseña", "recuperar contraseña", "password dimenticata", "reimposta password",
+			"esqueci minha senha", "redefinir senha", "wachtwoord vergeten", "nie pamiętasz hasła",
+			"забыли пароль", "восстановить пароль", "パスワードを忘れた", "パスワード再設定", "忘记密码",
+			"重置密码",
+		},
+		model.FormClassNewsletter: {
+			"newsletter", "subscribe", "subscription", "abonnieren", "s abonner", "abonnez vous",
+			"suscribirse", "suscríbete", "iscriviti", "assinar", "inscrever se", "inschrijven", "abonneren",
+			"subskrybuj", "подписаться", "購読", "订阅",
+		},
+	}
+}
+
+// With returns the keywords with the extra keywords added, extra can add new form classes.
+func (k FormKeywords) With(extra FormKeywords) FormKeywords {
+	merged := FormKeywords{}
+	for class, words := range k {
+		merged[class] = append([]string{}, words...)
+	}
+	for class, words := range extra {
+		merged[class] = append(merged[class], words...)
+	}
+
+	return merged
+}
+
+// normalizeText lower cases the text and turns the runs of punctuation and space into a single space.
+func normalizeText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	}), " ")
+}
+
+// matchKeyword reports whether the normalized text has the keyword. The scripts written without spaces
+// between the words have no word boundaries, their keywords match anywhere.
+func matchKeyword(text, keyword string) bool {
+	for _, r := range keyword {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) {
+			return strings.Contains(text, keyword)
+		}
+	}
+
+	return strings.Contains(" "+text+" ", " "+keyword+" ")
+}
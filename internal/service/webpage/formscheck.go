@@ -0,0 +1,309 @@
+package webpage
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"golang.org/x/net/html"
+)
+
+const (
+	// submitWeight is the score of a keyword on the submit controls, they name what the form does.
+	submitWeight = 3
+	// textWeight is the score of a keyword in the rest of the form, its labels, names and placeholders.
+	textWeight = 1
+	// minFormScore is the score a form needs to be classified.
+	minFormScore = 2
+)
+
+// formClassOrder breaks the ties between the form classes, the more specific classes first.
+var formClassOrder = []string{
+	model.FormClassPasswordReset,
+	model.FormClassSignup,
+	model.FormClassLogin,
+	model.FormClassSearch,
+	model.FormClassNewsletter,
+}
+
+// csrfNames are the parts of the names of the hidden inputs holding an anti CSRF token.
+var csrfNames = []string{"csrf", "xsrf", "token", "nonce", "requestverification"}
+
+// formsCheck lists the forms of the page with their fields and classifies them by their keywords and
+// their fields, a password field next to a user name makes a login form and a confirmation field a signup form.
+type formsCheck struct {
+	keywords map[string][]string
+	forms    []*formState
+	form     *formState
+	// the text of the submit button or of the label or legend being read
+	inSubmit bool
+	inLabel  bool
+}
+
+// formState is a form being read.
+type formState struct {
+	form       *model.Form
+	action     string
+	role       string
+	submitText strings.Builder
+	text       strings.Builder
+
+	passwords        int
+	newPasswords     int
+	currentPasswords int
+	emails           int
+	textInputs       int
+	search           bool
+}
+
+func newFormsCheck(keywords FormKeywords) NewCheck {
+	normalized := map[string][]string{}
+	for class, words := range keywords {
+		for _, w := range words {
+			if w = normalizeText(w); w != "" {
+				normalized[class] = append(normalized[class], w)
+			}
+		}
+	}
+
+	return func() Check {
+		return &formsCheck{keywords: normalized}
+	}
+}
+
+func (c *formsCheck) Name() string {
+	return "forms"
+}
+
+func (c *formsCheck) Token(t *Token) {
+	switch {
+	case t.IsStartTag() && t.Data == "form":
+		// browsers ignore a form nested in another form
+		if c.form == nil {
+			c.form = newFormState(t)
+		}
+	case c.form == nil:
+		return
+	case t.IsStartTag():
+		c.control(t)
+	case t.Type == html.EndTagToken:
+		switch t.Data {
+		case "form":
+			c.forms = append(c.forms, c.form)
+			c.form = nil
+		case "button":
+			c.inSubmit = false
+		case "label", "legend":
+			c.inLabel = false
+		}
+	case t.Type == html.TextToken:
+		if c.inSubmit {
+			c.form.submitText.WriteString(" " + t.Data)
+		} else if c.inLabel {
+			c.form.text.WriteString(" " + t.Data)
+		}
+	}
+}
+
+func newFormState(t *Token) *formState {
+	f := &formState{form: &model.Form{Fields: []*model.FormField{}, Line: t.Line}}
+	f.form.Id, _ = t.AttrValue("id")
+	f.form.Name, _ = t.AttrValue("name")
+	f.action, _ = t.AttrValue("action")
+	f.role, _ = t.AttrValue("role")
+
+	f.form.Method = "GET"
+	if method, _ := t.AttrValue("method"); strings.TrimSpace(method) != "" {
+		f.form.Method = strings.ToUpper(strings.TrimSpace(method))
+	}
+
+	f.addText(t, "id", "name", "class", "action", "aria-label", "title")
+
+	return f
+}
+
+// control reads the fields, the submit controls and the labels of the form.
+func (c *formsCheck) control(t *Token) {
+	f := c.form
+	switch t.Data {
+	case "input":
+		typ, _ := t.AttrValue("type")
+		typ = strings.ToLower(strings.TrimSpace(typ))
+		if typ == "" {
+			typ = "text"
+		}
+
+		switch typ {
+		case "submit", "image":
+			f.addSubmitText(t, "value", "alt", "aria-label", "title")
+			return
+		case "button", "reset":
+			return
+		}
+
+		f.addField(t, typ)
+	case "select", "textarea":
+		f.addField(t, t.Data)
+	case "button":
+		typ, _ := t.AttrValue("type")
+		switch strings.ToLower(strings.TrimSpace(typ)) {
+		case "", "submit":
+			f.addSubmitText(t, "value", "aria-label", "title")
+			c.inSubmit = t.Type == html.StartTagToken
+		}
+	case "img":
+		// the image of a submit button names it
+		if c.inSubmit {
+			f.addSubmitText(t, "alt")
+		}
+	case "label", "legend":
+		c.inLabel = t.Type == html.StartTagToken
+	}
+}
+
+func (f *formState) addField(t *Token, typ string) {
+	field := &model.FormField{Type: typ}
+	field.Name, _ = t.AttrValue("name")
+	_, field.Required = t.AttrValue("required")
+	f.form.Fields = append(f.form.Fields, field)
+
+	if typ == "hidden" {
+		name := strings.ToLower(field.Name)
+		for _, n := range csrfNames {
+			f.form.HasCsrfToken = f.form.HasCsrfToken || strings.Contains(name, n)
+		}
+		return
+	}
+
+	autocomplete, _ := t.AttrValue("autocomplete")
+	autocomplete = strings.ToLower(autocomplete)
+	switch {
+	case typ == "password":
+		f.passwords++
+		if strings.Contains(autocomplete, "new-password") {
+			f.newPasswords++
+		}
+		if strings.Contains(autocomplete, "current-password") {
+			f.currentPasswords++
+		}
+	case typ == "email" || strings.Contains(autocomplete, "email"):
+		f.emails++
+	case typ == "search":
+		f.search = true
+	case typ == "text":
+		f.textInputs++
+	}
+
+	f.addText(t, "name", "id", "placeholder", "aria-label", "title")
+}
+
+func (f *formState) addText(t *Token, keys ...string) {
+	writeAttrs(&f.text, t, keys)
+}
+
+func (f *formState) addSubmitText(t *Token, keys ...string) {
+	writeAttrs(&f.submitText, t, keys)
+}
+
+func writeAttrs(b *strings.Builder, t *Token, keys []string) {
+	for _, key := range keys {
+		if v, ok := t.AttrValue(key); ok {
+			b.WriteString(" " + v)
+		}
+	}
+}
+
+func (c *formsCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	// a form left open ends with the document
+	if c.form != nil {
+		c.forms = append(c.forms, c.form)
+		c.form = nil
+	}
+
+	section := &model.FormsSection{Counts: map[string]int{}, Forms: []*model.Form{}}
+	for _, f := range c.forms {
+		f.form.Action = doc.Url.String()
+		if strings.TrimSpace(f.action) != "" {
+			if action, err := doc.Resolve(f.action); err == nil {
+				f.form.Action = action.String()
+			}
+		}
+		f.form.PostsOverHttp = strings.HasPrefix(strings.ToLower(f.form.Action), "http:")
+
+		f.form.Class = c.classify(f)
+		section.Counts[f.form.Class]++
+		section.Forms = append(section.Forms, f.form)
+	}
+
+	analysis.HasLoginForm = section.Counts[model.FormClassLogin] > 0
+	analysis.Sections[c.Name()] = section
+
+	return nil
+}
+
+// classify scores the form classes by their keywords found in the form and by the fields of the
+// form, the class with the highest score wins.
+func (c *formsCheck) classify(f *formState) string {
+	scores := map[string]int{}
+	submitText := normalizeText(f.submitText.String())
+	text := normalizeText(f.text.String())
+	for class, words := range c.keywords {
+		for _, w := range words {
+			if matchKeyword(submitText, w) {
+				scores[class] += submitWeight
+			}
+			if matchKeyword(text, w) {
+				scores[class] += textWeight
+			}
+		}
+	}
+
+	switch {
+	case f.passwords >= 2 || f.newPasswords > 0:
+		scores[model.FormClassSignup] += 3
+	case f.passwords == 1 && f.textInputs+f.emails > 0:
+		scores[model.FormClassLogin] += 2
+		if f.currentPasswords > 0 {
+			scores[model.FormClassLogin] += 2
+		}
+	case f.passwords == 1:
+		// a password alone confirms an action as often as it logs in, it takes a keyword too
+		scores[model.FormClassLogin]++
+	case f.emails > 0:
+		scores[model.FormClassPasswordReset]++
+		scores[model.FormClassNewsletter]++
+	}
+	if f.search || strings.EqualFold(strings.TrimSpace(f.role), "search") {
+		scores[model.FormClassSearch] += 3
+	} else if f.passwords == 0 && f.emails == 0 && f.textInputs == 1 && f.form.Method == "GET" {
+		scores[model.FormClassSearch]++
+	}
+
+	class, best := model.FormClassOther, minFormScore-1
+	for _, candidate := range formClasses(c.keywords) {
+		if scores[candidate] > best {
+			class, best = candidate, scores[candidate]
+		}
+	}
+
+	return class
+}
+
+// formClasses returns the known classes in their tie break order followed by the configured ones.
+func formClasses(keywords map[string][]string) []string {
+	classes := append([]string{}, formClassOrder...)
+	extra := []string{}
+	for class := range keywords {
+		known := false
+		for _, k := range formClassOrder {
+			known = known || k == class
+		}
+		if !known {
+			extra = append(extra, class)
+		}
+	}
+	sort.Strings(extra)
+
+	return append(classes, extra...)
+}
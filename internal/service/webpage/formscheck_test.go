@@ -0,0 +1,136 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func classesOf(section *model.FormsSection) []string {
+	classes := []string{}
+	for _, f := range section.Forms {
+		classes = append(classes, f.Class)
+	}
+
+	return classes
+}
+
+func Test_forms_check_should_list_the_forms_with_their_fields(t *testing.T) {
+	analysis, err := runCheck(newFormsCheck(DefaultFormKeywords())(), "https://www.test.com/account", `<html><body>
+<form id="login" method="post" action="http://www.test.com/session">
+	<input type="hidden" name="authenticity_token" value="abc">
+	<input type="email" name="email" required>
+	<input type="password" name="password" autocomplete="current-password">
+	<select name="lang"><option>en</option></select>
+	<input type="image" src="/go.png" alt="Sign in">
+</form>
+<form role="search"><input type="search" name="q"></form>
+</body></html>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.FormsSection](t, analysis, "forms")
+
+	assert.True(t, analysis.HasLoginForm)
+	require.Len(t, section.Forms, 2)
+	assert.Equal(t, &model.Form{
+		Id:     "login",
+		Class:  model.FormClassLogin,
+		Method: "POST",
+		Action: "http://www.test.com/session",
+		Fields: []*model.FormField{
+			{Name: "authenticity_token", Type: "hidden"},
+			{Name: "email", Type: "email", Required: true},
+			{Name: "password", Type: "password"},
+			{Name: "lang", Type: "select"},
+		},
+		HasCsrfToken:  true,
+		PostsOverHttp: true,
+		Line:          2,
+	}, section.Forms[0])
+
+	search := section.Forms[1]
+	assert.Equal(t, model.FormClassSearch, search.Class)
+	assert.Equal(t, "GET", search.Method)
+	assert.Equal(t, "https://www.test.com/account", search.Action)
+	assert.False(t, search.PostsOverHttp)
+	assert.Equal(t, map[string]int{model.FormClassLogin: 1, model.FormClassSearch: 1}, section.Counts)
+}
+
+func Test_forms_check_should_classify_the_forms(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		input string
+		class string
+	}{
+		{
+			desc: "a button with nested text names a login form",
+			input: `<form><input name="user"><input type="password">
+				<button><span class="icon"></span><span>Log in</span></button></form>`,
+			class: model.FormClassLogin,
+		},
+		{
+			desc:  "a localised label names a login form",
+			input: `<form><input name="u"><input type="password"><button>Anmelden</button></form>`,
+			class: model.FormClassLogin,
+		},
+		{
+			desc: "a password confirmation makes a signup form",
+			input: `<form><input type="email"><input type="password"><input type="password">
+				<button>Créer un compte</button></form>`,
+			class: model.FormClassSignup,
+		},
+		{
+			desc:  "a reset request without a password is a password reset form",
+			input: `<form><input type="email" name="email"><button>Passwort zurücksetzen</button></form>`,
+			class: model.FormClassPasswordReset,
+		},
+		{
+			desc:  "an email form with a subscribe button is a newsletter form",
+			input: `<form><input type="email" placeholder="Your email"><input type="submit" value="Suscríbete"></form>`,
+			class: model.FormClassNewsletter,
+		},
+		{
+			desc:  "a keyword in a script without spaces matches",
+			input: `<form><input type="text" name="q"><button>搜索一下</button></form>`,
+			class: model.FormClassSearch,
+		},
+		{
+			desc: "a password without an identifier field is another form",
+			input: `<form method="post"><input type="password" name="pin" autocomplete="current-password">
+				<button>Delete my account</button></form>`,
+			class: model.FormClassOther,
+		},
+		{
+			desc:  "a password without an identifier field and with a keyword names a login form",
+			input: `<form method="post"><input type="password" name="password"><button>Sign in</button></form>`,
+			class: model.FormClassLogin,
+		},
+		{
+			desc:  "a form without keywords is another form",
+			input: `<form method="post"><input name="name"><textarea name="message"></textarea><button>Send</button></form>`,
+			class: model.FormClassOther,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			analysis, err := runCheck(newFormsCheck(DefaultFormKeywords())(), "https://www.test.com", tc.input)
+			require.NoError(t, err)
+			section := sectionOf[*model.FormsSection](t, analysis, "forms")
+
+			assert.Equal(t, []string{tc.class}, classesOf(section))
+		})
+	}
+}
+
+func Test_forms_check_should_use_the_configured_keywords(t *testing.T) {
+	keywords := DefaultFormKeywords().With(FormKeywords{"contact": {"Send message"}})
+	analysis, err := runCheck(newFormsCheck(keywords)(), "https://www.test.com", `<form method="post">
+<input name="name"><textarea name="message"></textarea><button>Send message</button>
+</form>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.FormsSection](t, analysis, "forms")
+
+	assert.Equal(t, []string{"contact"}, classesOf(section))
+}
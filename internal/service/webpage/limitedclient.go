@@ -0,0 +1,59 @@
+package webpage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/limiter"
+)
+
+// limitedClient is a WebClient whose requests go through a shared limiter.Limiter, the slot of
+// a request is held until its response body is closed.
+type limitedClient struct {
+	client  WebClient
+	limiter limiter.Limiter
+}
+
+func NewLimitedClient(client WebClient, l limiter.Limiter) WebClient {
+	return &limitedClient{
+		client:  client,
+		limiter: l,
+	}
+}
+
+func (c *limitedClient) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+func (c *limitedClient) Do(req *http.Request) (*http.Response, error) {
+	release, err := c.limiter.Acquire(req.Context(), strings.ToLower(req.URL.Host))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	res.Body = &releasingBody{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
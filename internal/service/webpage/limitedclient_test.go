@@ -0,0 +1,49 @@
+package webpage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/limiter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_limited_client_should_hold_the_slot_until_the_body_is_closed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLimitedClient(client, limiter.New(&limiter.Config{MaxConcurrent: 1}))
+
+	res, err := sut.Get(context.Background(), srv.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = sut.Get(ctx, srv.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, res.Body.Close())
+	res, err = sut.Get(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+}
+
+func Test_limited_client_should_release_the_slot_when_the_request_fails(t *testing.T) {
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLimitedClient(client, limiter.New(&limiter.Config{MaxConcurrent: 1}))
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		_, err := sut.Get(ctx, "http://127.0.0.1:0/unreachable")
+		cancel()
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	}
+}
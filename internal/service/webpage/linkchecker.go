@@ -0,0 +1,173 @@
+package webpage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxCheckedLinks is the number of cached link statuses after which the expired ones are pruned.
+	maxCheckedLinks = 4096
+	// maxDrainSize is the part of a response body read before closing it, so the connection can be reused.
+	maxDrainSize = 4 << 10
+)
+
+// LinkChecker checks whether the links of a page are reachable.
+type LinkChecker interface {
+	// Check returns the status of the absolute link and the http status code of its response,
+	// -1 when the request failed and 0 when it was not sent.
+	Check(ctx context.Context, link string) (model.LinkStatus, int)
+}
+
+type linkChecker struct {
+	client WebClient
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	links map[string]*checkedLink
+}
+
+type checkedLink struct {
+	// checked is closed once status, statusCode and expires are set.
+	checked    chan struct{}
+	status     model.LinkStatus
+	statusCode int
+	expires    time.Time
+}
+
+// NewLinkChecker checks the links with a HEAD request, falling back to a ranged GET for the servers
+// which don't support HEAD. The statuses are cached for the ttl, 0 disables the cache.
+func NewLinkChecker(client WebClient, ttl time.Duration) LinkChecker {
+	return &linkChecker{
+		client: client,
+		ttl:    ttl,
+		links:  map[string]*checkedLink{},
+	}
+}
+
+// Check shares a single request between the concurrent checks of a link, a check interrupted by
+// the ctx is not cached.
+func (c *linkChecker) Check(ctx context.Context, link string) (model.LinkStatus, int) {
+	if c.ttl <= 0 {
+		return c.check(ctx, link)
+	}
+
+	c.mu.Lock()
+	l, ok := c.links[link]
+	if ok {
+		select {
+		case <-l.checked:
+			if time.Now().After(l.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+
+	if !ok {
+		c.prune()
+		l = &checkedLink{checked: make(chan struct{})}
+		c.links[link] = l
+		c.mu.Unlock()
+
+		l.status, l.statusCode = c.check(ctx, link)
+		l.expires = time.Now().Add(c.ttl)
+		if ctx.Err() != nil {
+			l.expires = time.Time{}
+		}
+		close(l.checked)
+		return l.status, l.statusCode
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-l.checked:
+		if l.expires.IsZero() && ctx.Err() == nil {
+			// the shared check was interrupted by the ctx of another caller
+			return c.check(ctx, link)
+		}
+		return l.status, l.statusCode
+	case <-ctx.Done():
+		return model.LinkStatusInactive, -1
+	}
+}
+
+// prune removes the expired statuses once the cache grows large, c.mu must be held.
+func (c *linkChecker) prune() {
+	if len(c.links) < maxCheckedLinks {
+		return
+	}
+
+	now := time.Now()
+	for k, l := range c.links {
+		select {
+		case <-l.checked:
+			if now.After(l.expires) {
+				delete(c.links, k)
+			}
+		default:
+		}
+	}
+}
+
+func (c *linkChecker) check(ctx context.Context, link string) (model.LinkStatus, int) {
+	logrus.Infof("checking for link %q status", link)
+
+	ranged := false
+	res, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && (res.StatusCode == http.StatusMethodNotAllowed ||
+		res.StatusCode == http.StatusNotImplemented) {
+		logrus.Infof("%q doesn't support HEAD, checking it with a ranged GET", link)
+		ranged = true
+		res, err = c.request(ctx, http.MethodGet, link)
+	}
+
+	if errors.Is(err, BlockedByRobotsErr) {
+		return model.LinkStatusBlocked, 0
+	}
+
+	if err != nil {
+		return model.LinkStatusInactive, -1
+	}
+
+	// an empty resource has no first byte to send to the ranged GET
+	if (res.StatusCode >= 200 && res.StatusCode < 300) ||
+		(ranged && res.StatusCode == http.StatusRequestedRangeNotSatisfiable) {
+		return model.LinkStatusActive, res.StatusCode
+	}
+
+	return model.LinkStatusInactive, res.StatusCode
+}
+
+// request sends the check request and closes the response, a GET asks for the first byte only.
+func (c *linkChecker) request(ctx context.Context, method, link string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(io.Discard, io.LimitReader(res.Body, maxDrainSize)); err != nil {
+		logrus.Warnf("Error while reading the response body, %v", err)
+	}
+	if err := res.Body.Close(); err != nil {
+		logrus.Warnf("Error while closing the response body, %v", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,155 @@
+package webpage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_link_checker_should_check_the_link_with_head(t *testing.T) {
+	methods := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, 0)
+
+	status, code := sut.Check(context.Background(), srv.URL)
+
+	assert.Equal(t, model.LinkStatusActive, status)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{http.MethodHead}, methods)
+}
+
+func Test_link_checker_should_fall_back_to_a_ranged_get_when_head_is_rejected(t *testing.T) {
+	for _, rejected := range []int{http.StatusMethodNotAllowed, http.StatusNotImplemented} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(rejected)
+				return
+			}
+
+			assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+			w.Header().Set("Content-Range", "bytes 0-0/5")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte("h"))
+		}))
+
+		client, err := NewWebClient(&ClientConfig{})
+		require.NoError(t, err)
+		sut := NewLinkChecker(client, 0)
+
+		status, code := sut.Check(context.Background(), srv.URL)
+
+		assert.Equal(t, model.LinkStatusActive, status)
+		assert.Equal(t, http.StatusPartialContent, code)
+		srv.Close()
+	}
+}
+
+func Test_link_checker_should_report_an_empty_resource_rejecting_the_range_as_active(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Range", "bytes */0")
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, 0)
+
+	status, code := sut.Check(context.Background(), srv.URL)
+
+	assert.Equal(t, model.LinkStatusActive, status)
+	assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, code)
+}
+
+func Test_link_checker_should_report_any_success_status_as_active(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, 0)
+
+	status, code := sut.Check(context.Background(), srv.URL)
+
+	assert.Equal(t, model.LinkStatusActive, status)
+	assert.Equal(t, http.StatusNoContent, code)
+}
+
+func Test_link_checker_should_report_the_status_code_of_a_broken_link(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, 0)
+
+	status, code := sut.Check(context.Background(), srv.URL)
+
+	assert.Equal(t, model.LinkStatusInactive, status)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func Test_link_checker_should_reuse_the_cached_status_until_it_expires(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, 100*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		status, _ := sut.Check(context.Background(), srv.URL)
+		assert.Equal(t, model.LinkStatusActive, status)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	time.Sleep(150 * time.Millisecond)
+	sut.Check(context.Background(), srv.URL)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func Test_link_checker_should_not_cache_an_interrupted_check(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewLinkChecker(client, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, code := sut.Check(ctx, srv.URL)
+	assert.Equal(t, model.LinkStatusInactive, status)
+	assert.Equal(t, -1, code)
+
+	status, code = sut.Check(context.Background(), srv.URL)
+	assert.Equal(t, model.LinkStatusActive, status)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
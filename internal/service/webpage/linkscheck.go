@@ -0,0 +1,184 @@
+package webpage
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/html"
+)
+
+// linksCheck collects the links of the document and checks them concurrently. The hrefs are resolved
+// against the base url of the document.
+type linksCheck struct {
+	checker LinkChecker
+	links   []*model.Link
+	// anchors are the ids and <a name> of the document, the targets of the fragment links
+	anchors       map[string]bool
+	insideLinkTag bool
+}
+
+func newLinksCheck(checker LinkChecker) NewCheck {
+	return func() Check {
+		return &linksCheck{
+			checker: checker,
+			links:   []*model.Link{},
+			anchors: map[string]bool{},
+		}
+	}
+}
+
+func (c *linksCheck) Name() string {
+	return "links"
+}
+
+func (c *linksCheck) Token(t *Token) {
+	switch {
+	case t.Type == html.EndTagToken:
+		if t.Data == "a" && c.insideLinkTag {
+			c.insideLinkTag = false
+		}
+	case t.IsStartTag():
+		for _, a := range t.Attr {
+			switch {
+			case a.Key == "id", t.Data == "a" && a.Key == "name":
+				c.anchors[a.Val] = true
+			case t.Data == "a" && a.Key == "href" && !c.insideLinkTag:
+				c.links = append(c.links, &model.Link{Url: a.Val})
+				c.insideLinkTag = t.Type == html.StartTagToken
+			}
+		}
+	case t.Type == html.TextToken:
+		if c.insideLinkTag {
+			c.links[len(c.links)-1].Name = t.Data
+		}
+	}
+}
+
+func (c *linksCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	wg := sync.WaitGroup{}
+	for _, l := range c.links {
+		l.Type = linkType(l.Url)
+		resolved, err := doc.Resolve(l.Url)
+		if err == nil {
+			l.ResolvedUrl = resolved.String()
+			l.IsInternal = isInternalLink(doc.Url, resolved)
+		}
+
+		switch {
+		case l.Type == model.LinkTypeFragment:
+			l.IsInternal = true
+			l.LinkStatus = model.LinkStatusInactive
+			if hasAnchor(c.anchors, strings.TrimSpace(l.Url)[1:]) {
+				l.LinkStatus = model.LinkStatusActive
+			}
+			continue
+		case l.Type != model.LinkTypeHttp:
+			l.LinkStatus = model.LinkStatusNotChecked
+			continue
+		case err != nil:
+			logrus.Errorf("unable to parse linkUrl %q, %v", l.Url, err)
+			l.LinkStatus = model.LinkStatusInactive
+			l.HttpStatusCode = -1
+			continue
+		}
+
+		wg.Add(1)
+		go func(l *model.Link, resolved *url.URL) {
+			defer wg.Done()
+			l.LinkStatus, l.HttpStatusCode = c.linkStatus(ctx, resolved)
+		}(l, resolved)
+	}
+	wg.Wait()
+
+	analysis.Links = c.links
+	analysis.LinkTypeCounts = map[model.LinkType]int{}
+	for _, l := range c.links {
+		if l.IsInternal {
+			analysis.InternalLinkCount++
+		} else {
+			analysis.ExternalLinkCount++
+		}
+
+		analysis.LinkTypeCounts[l.Type]++
+		switch l.LinkStatus {
+		case model.LinkStatusActive:
+			analysis.ActiveLinkCount++
+		case model.LinkStatusBlocked:
+			analysis.BlockedLinkCount++
+		case model.LinkStatusNotChecked:
+		default:
+			analysis.InactiveLinkCount++
+		}
+	}
+
+	return nil
+}
+
+// linkType classifies the href by its scheme, hrefs without a scheme are relative http links.
+func linkType(href string) model.LinkType {
+	href = strings.TrimSpace(href)
+	if strings.HasPrefix(href, "#") {
+		return model.LinkTypeFragment
+	}
+
+	scheme, _, ok := strings.Cut(href, ":")
+	if !ok || !isScheme(scheme) {
+		return model.LinkTypeHttp
+	}
+
+	switch strings.ToLower(scheme) {
+	case "http", "https":
+		return model.LinkTypeHttp
+	case "mailto":
+		return model.LinkTypeMailto
+	case "tel":
+		return model.LinkTypeTel
+	case "javascript":
+		return model.LinkTypeJavascript
+	default:
+		return model.LinkTypeOther
+	}
+}
+
+// isScheme reports whether s is a valid url scheme, `ALPHA *( ALPHA / DIGIT / "+" / "-" / "." )`.
+func isScheme(s string) bool {
+	for i, c := range s {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+// hasAnchor reports whether the fragment points to an anchor of the document, the empty fragment
+// and `top` scroll to the top of the document.
+func hasAnchor(anchors map[string]bool, fragment string) bool {
+	if fragment == "" || strings.EqualFold(fragment, "top") || anchors[fragment] {
+		return true
+	}
+
+	decoded, err := url.PathUnescape(fragment)
+	return err == nil && anchors[decoded]
+}
+
+// isInternalLink reports whether the resolved link points to the host of the document.
+func isInternalLink(document, link *url.URL) bool {
+	return strings.EqualFold(link.Hostname(), document.Hostname())
+}
+
+// linkStatus checks the resolved link, the fragment only matters to the browser and is not sent.
+func (c *linksCheck) linkStatus(ctx context.Context, link *url.URL) (model.LinkStatus, int) {
+	target := *link
+	target.Fragment = ""
+	target.RawFragment = ""
+
+	return c.checker.Check(ctx, target.String())
+}
@@ -0,0 +1,104 @@
+package webpage
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+)
+
+// passiveTypes are the resource types browsers load over http on an https page, with a warning.
+var passiveTypes = map[string]bool{
+	model.ResourceTypeImage: true,
+	model.ResourceTypeIcon:  true,
+	model.ResourceTypeMedia: true,
+}
+
+// mixedContentCheck finds the resources and the form actions of an https page which use http, the
+// same resources as the resource inventory are walked.
+type mixedContentCheck struct {
+	refs    resourceRefs
+	actions []*resourceRef
+	metaCsp []string
+}
+
+func newMixedContentCheck() Check {
+	return &mixedContentCheck{}
+}
+
+func (c *mixedContentCheck) Name() string {
+	return "mixedContent"
+}
+
+func (c *mixedContentCheck) Token(t *Token) {
+	c.refs.token(t)
+	if !t.IsStartTag() {
+		return
+	}
+
+	key := ""
+	switch t.Data {
+	case "form":
+		key = "action"
+	case "button", "input":
+		key = "formaction"
+	case "meta":
+		equiv, _ := t.AttrValue("http-equiv")
+		if strings.EqualFold(strings.TrimSpace(equiv), "content-security-policy") {
+			content, _ := t.AttrValue("content")
+			c.metaCsp = append(c.metaCsp, content)
+		}
+	}
+
+	if action, ok := t.AttrValue(key); key != "" && ok && strings.TrimSpace(action) != "" {
+		c.actions = append(c.actions, &resourceRef{typ: model.MixedContentTypeForm, url: action, line: t.Line})
+	}
+}
+
+func (c *mixedContentCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	section := &model.MixedContentSection{
+		Https:   strings.EqualFold(doc.Url.Scheme, "https"),
+		Active:  []*model.MixedContent{},
+		Passive: []*model.MixedContent{},
+	}
+	analysis.Sections[c.Name()] = section
+	if !section.Https {
+		return nil
+	}
+
+	for _, p := range append(c.metaCsp, doc.Page.Headers.Values("Content-Security-Policy")...) {
+		if _, ok := cspDirectives(p)["upgrade-insecure-requests"]; ok {
+			section.UpgradeInsecureRequests = true
+		}
+	}
+
+	refs := append(c.refs.list, c.actions...)
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].line < refs[j].line })
+
+	seen := map[string]bool{}
+	for _, r := range refs {
+		resolved, err := doc.Resolve(r.url)
+		if err != nil || !strings.EqualFold(resolved.Scheme, "http") {
+			continue
+		}
+
+		key := r.typ + " " + resolved.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		mixed := &model.MixedContent{Type: r.typ, Url: resolved.String(), Line: r.line}
+		if passiveTypes[r.typ] {
+			section.Passive = append(section.Passive, mixed)
+		} else {
+			section.Active = append(section.Active, mixed)
+		}
+	}
+
+	section.ActiveCount = len(section.Active)
+	section.PassiveCount = len(section.Passive)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mixed_content_check_should_report_the_http_urls_of_an_https_page(t *testing.T) {
+	content := `<html>
+<head>
+	<script src="http://cdn.test.com/app.js"></script>
+	<link rel="stylesheet" href="//www.test.com/style.css">
+	<link rel="icon" href="http://www.test.com/favicon.ico">
+</head>
+<body>
+	<img src="http://img.test.com/logo.png" srcset="https://img.test.com/logo.png 2x">
+	<iframe src="http://video.test.com/embed"></iframe>
+	<video src="http://video.test.com/clip.mp4"></video>
+	<form action="http://www.test.com/login"><button formaction="/search">Go</button></form>
+	<img src="http://img.test.com/logo.png">
+	<a href="http://www.test.com/about">about</a>
+</body>
+</html>`
+	analysis, err := runCheck(newMixedContentCheck(), "https://www.test.com", content)
+	require.NoError(t, err)
+
+	section, ok := analysis.Sections["mixedContent"].(*model.MixedContentSection)
+	require.True(t, ok)
+	assert.True(t, section.Https)
+	assert.False(t, section.UpgradeInsecureRequests)
+	assert.Equal(t, 3, section.ActiveCount)
+	assert.Equal(t, []*model.MixedContent{
+		{Type: model.ResourceTypeScript, Url: "http://cdn.test.com/app.js", Line: 3},
+		{Type: model.ResourceTypeIframe, Url: "http://video.test.com/embed", Line: 9},
+		{Type: model.MixedContentTypeForm, Url: "http://www.test.com/login", Line: 11},
+	}, section.Active)
+	assert.Equal(t, 3, section.PassiveCount)
+	assert.Equal(t, []*model.MixedContent{
+		{Type: model.ResourceTypeIcon, Url: "http://www.test.com/favicon.ico", Line: 5},
+		{Type: model.ResourceTypeImage, Url: "http://img.test.com/logo.png", Line: 8},
+		{Type: model.ResourceTypeMedia, Url: "http://video.test.com/clip.mp4", Line: 10},
+	}, section.Passive)
+}
+
+func Test_mixed_content_check_should_ignore_the_http_pages(t *testing.T) {
+	content := `<html><body><script src="http://cdn.test.com/app.js"></script></body></html>`
+	analysis, err := runCheck(newMixedContentCheck(), "http://www.test.com", content)
+	require.NoError(t, err)
+
+	section := analysis.Sections["mixedContent"].(*model.MixedContentSection)
+	assert.False(t, section.Https)
+	assert.Empty(t, section.Active)
+	assert.Empty(t, section.Passive)
+}
+
+func Test_mixed_content_check_should_detect_upgrade_insecure_requests(t *testing.T) {
+	content := `<html><head>
+<meta http-equiv="Content-Security-Policy" content="upgrade-insecure-requests">
+</head><body><img src="http://img.test.com/logo.png"></body></html>`
+	analysis, err := runCheck(newMixedContentCheck(), "https://www.test.com", content)
+	require.NoError(t, err)
+
+	section := analysis.Sections["mixedContent"].(*model.MixedContentSection)
+	assert.True(t, section.UpgradeInsecureRequests)
+	assert.Equal(t, 1, section.PassiveCount)
+}
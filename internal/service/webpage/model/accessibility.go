@@ -0,0 +1,9 @@
+package model
+
+// AccessibilitySection is the accessibility audit of the page, HeadingOrder lists the headings of the
+// page in document order, like `["h1", "h2", "h2", "h3"]`.
+type AccessibilitySection struct {
+	Lang         string   `json:"lang"`
+	HeadingOrder []string `json:"headingOrder"`
+	Issues       []*Issue `json:"issues"`
+}
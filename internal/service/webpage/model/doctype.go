@@ -0,0 +1,23 @@
+package model
+
+// RenderingMode is the mode browsers render the page in, chosen from the doctype.
+type RenderingMode string
+
+var (
+	RenderingModeStandards       RenderingMode = "standards"
+	RenderingModeAlmostStandards RenderingMode = "almost-standards"
+	RenderingModeQuirks          RenderingMode = "quirks"
+)
+
+// DoctypeNone is the name of the doctype of a page without one.
+var DoctypeNone = "none"
+
+// Doctype is the doctype of the page. Name is the normalized name, like HTML5 or XHTML 1.0 Strict,
+// Root is the root element the doctype declares.
+type Doctype struct {
+	Name          string        `json:"name"`
+	Root          string        `json:"root,omitempty"`
+	PublicId      string        `json:"publicId,omitempty"`
+	SystemId      string        `json:"systemId,omitempty"`
+	RenderingMode RenderingMode `json:"renderingMode"`
+}
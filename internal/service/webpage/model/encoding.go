@@ -0,0 +1,16 @@
+package model
+
+// Encoding describes the character encoding of a page. HeaderCharset and MetaCharset are the charsets
+// the Content-Type header and the <meta> of the document declare, Bom is the encoding of the byte order
+// mark. Detected is the encoding the bytes are valid in, empty for ASCII documents which are valid in
+// all of them. The content was decoded from Used, the first of Bom, HeaderCharset, MetaCharset and
+// Detected like browsers do. Mismatch is set when they disagree.
+type Encoding struct {
+	Declared      string `json:"declared,omitempty"`
+	HeaderCharset string `json:"headerCharset,omitempty"`
+	MetaCharset   string `json:"metaCharset,omitempty"`
+	Bom           string `json:"bom,omitempty"`
+	Detected      string `json:"detected,omitempty"`
+	Used          string `json:"used"`
+	Mismatch      bool   `json:"mismatch"`
+}
@@ -0,0 +1,39 @@
+package model
+
+// The form classes, a form matching none of them is FormClassOther.
+var (
+	FormClassLogin         = "login"
+	FormClassSignup        = "signup"
+	FormClassSearch        = "search"
+	FormClassPasswordReset = "password-reset"
+	FormClassNewsletter    = "newsletter"
+	FormClassOther         = "other"
+)
+
+// FormsSection is the inventory of the forms of the page, Counts are by form class.
+type FormsSection struct {
+	Counts map[string]int `json:"counts"`
+	Forms  []*Form        `json:"forms"`
+}
+
+// Form is a form of the page. Action is the absolute url the form submits to, the page itself when the
+// form has no action. PostsOverHttp forms send their data unencrypted.
+type Form struct {
+	Id            string       `json:"id,omitempty"`
+	Name          string       `json:"name,omitempty"`
+	Class         string       `json:"class"`
+	Method        string       `json:"method"`
+	Action        string       `json:"action"`
+	Fields        []*FormField `json:"fields"`
+	HasCsrfToken  bool         `json:"hasCsrfToken"`
+	PostsOverHttp bool         `json:"postsOverHttp"`
+	Line          int          `json:"line"`
+}
+
+// FormField is a control of a form, Type is the input type or the element name of the select and
+// textarea controls.
+type FormField struct {
+	Name     string `json:"name,omitempty"`
+	Type     string `json:"type"`
+	Required bool   `json:"required"`
+}
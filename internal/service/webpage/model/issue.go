@@ -0,0 +1,20 @@
+package model
+
+// Severity ranks the issues found by the checks.
+type Severity string
+
+var (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue is a finding of a check. Line and Element locate it in the document, they are empty when the
+// issue is about something missing from the document.
+type Issue struct {
+	Code     string   `json:"code"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Line     int      `json:"line,omitempty"`
+	Element  string   `json:"element,omitempty"`
+}
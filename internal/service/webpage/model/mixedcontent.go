@@ -0,0 +1,23 @@
+package model
+
+// MixedContentTypeForm is the type of the form actions, the other types are the resource types.
+var MixedContentTypeForm = "form"
+
+// MixedContentSection lists the http resources and form actions of an https page. Browsers block the
+// active content, scripts, stylesheets, iframes and the like, and load the passive content, images
+// and media, with a warning. With UpgradeInsecureRequests the browsers load them over https instead.
+type MixedContentSection struct {
+	Https                   bool            `json:"https"`
+	UpgradeInsecureRequests bool            `json:"upgradeInsecureRequests"`
+	ActiveCount             int             `json:"activeCount"`
+	PassiveCount            int             `json:"passiveCount"`
+	Active                  []*MixedContent `json:"active"`
+	Passive                 []*MixedContent `json:"passive"`
+}
+
+// MixedContent is a http url of an https page.
+type MixedContent struct {
+	Type string `json:"type"`
+	Url  string `json:"url"`
+	Line int    `json:"line"`
+}
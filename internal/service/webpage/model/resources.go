@@ -0,0 +1,38 @@
+package model
+
+var (
+	ResourceTypeScript     = "script"
+	ResourceTypeStylesheet = "stylesheet"
+	ResourceTypeFont       = "font"
+	ResourceTypeImage      = "image"
+	ResourceTypeIcon       = "icon"
+	ResourceTypeIframe     = "iframe"
+	ResourceTypeMedia      = "media"
+	// ResourceTypePreload are the preloaded resources of any other kind, like fetch or document.
+	ResourceTypePreload = "preload"
+)
+
+// ResourcesSection is the inventory of the resources the page loads, Counts are by resource type.
+// ThirdPartyDomains are the hosts of the external resources.
+type ResourcesSection struct {
+	Counts            map[string]int `json:"counts"`
+	InternalCount     int            `json:"internalCount"`
+	ExternalCount     int            `json:"externalCount"`
+	InactiveCount     int            `json:"inactiveCount"`
+	InlineScriptCount int            `json:"inlineScriptCount"`
+	InlineStyleCount  int            `json:"inlineStyleCount"`
+	ThirdPartyDomains []string       `json:"thirdPartyDomains"`
+	Resources         []*Resource    `json:"resources"`
+}
+
+// Resource is a resource loaded by the page, Status and HttpStatusCode are only set when the
+// resources are checked.
+type Resource struct {
+	Type           string     `json:"type"`
+	Url            string     `json:"url"`
+	ResolvedUrl    string     `json:"resolvedUrl"`
+	IsInternal     bool       `json:"isInternal"`
+	Status         LinkStatus `json:"status,omitempty"`
+	HttpStatusCode int        `json:"httpStatusCode,omitempty"`
+	Line           int        `json:"line"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Result grades a single header, cookie or the TLS connection of the page.
+type Result string
+
+var (
+	ResultPass    Result = "pass"
+	ResultWarning Result = "warning"
+	ResultFail    Result = "fail"
+)
+
+// SecuritySection is the audit of the security headers, cookies and TLS connection of the page.
+// Score goes from 0 to 100 and Grade from A to F, TLS is nil when the page is not served over https.
+type SecuritySection struct {
+	Score   int             `json:"score"`
+	Grade   string          `json:"grade"`
+	Https   bool            `json:"https"`
+	Headers []*HeaderResult `json:"headers"`
+	Cookies []*CookieResult `json:"cookies"`
+	TLS     *TLSResult      `json:"tls"`
+}
+
+type HeaderResult struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Result  Result `json:"result"`
+	Message string `json:"message"`
+}
+
+type CookieResult struct {
+	Name     string `json:"name"`
+	Secure   bool   `json:"secure"`
+	HttpOnly bool   `json:"httpOnly"`
+	SameSite string `json:"sameSite"`
+	Result   Result `json:"result"`
+	Message  string `json:"message"`
+}
+
+type TLSResult struct {
+	Version            string    `json:"version"`
+	CipherSuite        string    `json:"cipherSuite"`
+	CertificateSubject string    `json:"certificateSubject"`
+	CertificateIssuer  string    `json:"certificateIssuer"`
+	CertificateExpires time.Time `json:"certificateExpires"`
+	DaysToExpiry       int       `json:"daysToExpiry"`
+	Result             Result    `json:"result"`
+	Message            string    `json:"message"`
+}
@@ -0,0 +1,24 @@
+package model
+
+// SeoSection is the SEO audit of the <head> metadata of the page. Score goes from 0 to 100, every
+// error and warning of Issues lowers it.
+type SeoSection struct {
+	Score             int               `json:"score"`
+	Title             string            `json:"title"`
+	TitleLength       int               `json:"titleLength"`
+	Description       string            `json:"description"`
+	DescriptionLength int               `json:"descriptionLength"`
+	Canonical         string            `json:"canonical"`
+	Robots            string            `json:"robots"`
+	Hreflangs         []*Hreflang       `json:"hreflangs"`
+	OpenGraph         map[string]string `json:"openGraph"`
+	TwitterCard       map[string]string `json:"twitterCard"`
+	H1Count           int               `json:"h1Count"`
+	Issues            []*Issue          `json:"issues"`
+}
+
+// Hreflang is an alternate language version of the page, Url is resolved against the document.
+type Hreflang struct {
+	Lang string `json:"lang"`
+	Url  string `json:"url"`
+}
@@ -0,0 +1,23 @@
+package model
+
+var (
+	FormatJsonLd    = "json-ld"
+	FormatMicrodata = "microdata"
+	FormatRdfa      = "rdfa"
+)
+
+// StructuredDataSection lists the top level entities of the JSON-LD, Microdata and RDFa of the page.
+type StructuredDataSection struct {
+	Entities []*Entity `json:"entities"`
+	Issues   []*Issue  `json:"issues"`
+}
+
+// Entity is a structured data item, like a schema.org Product. Type is the @type, itemtype or typeof
+// of the item, multiple types are separated by spaces. The values of Properties are strings, nested
+// entities or lists of them when a property repeats, JSON-LD properties keep their JSON values.
+type Entity struct {
+	Format     string         `json:"format"`
+	Type       string         `json:"type"`
+	Properties map[string]any `json:"properties"`
+	Line       int            `json:"line"`
+}
@@ -1,10 +1,40 @@
 package model
 
+import (
+	"net/http"
+	"time"
+)
+
 type DownloadedWebpage struct {
 	StatusCode int
 	Status     string
+	// Url is the requested url, FinalUrl is the url of the page after following the Redirects.
+	Url       string
+	FinalUrl  string
+	Redirects []*Redirect
+	Headers   http.Header
+	// TLS is nil when the page was not served over https.
+	TLS *TLS
+	// Encoding is the character encoding the Content was transcoded to utf-8 from, nil when the page
+	// is not text.
+	Encoding *Encoding
+	Content  []byte
+}
+
+// TLS is the state of the connection the page was downloaded over.
+type TLS struct {
+	Version            string
+	CipherSuite        string
+	CertificateSubject string
+	CertificateIssuer  string
+	CertificateExpires time.Time
+}
+
+// Redirect is a single hop of a redirect chain.
+type Redirect struct {
 	Url        string
-	Content    []byte
+	StatusCode int
+	Location   string
 }
 
 type Analysis struct {
@@ -16,8 +46,12 @@ type Analysis struct {
 	ExternalLinkCount int
 	ActiveLinkCount   int
 	InactiveLinkCount int
+	BlockedLinkCount  int
+	LinkTypeCounts    map[LinkType]int
 	PageVersion       string
 	HasLoginForm      bool
+	// Sections are the findings of the checks, by check name.
+	Sections map[string]any
 }
 
 type LinkStatus string
@@ -25,11 +59,31 @@ type LinkStatus string
 var (
 	LinkStatusActive   LinkStatus = "Active"
 	LinkStatusInactive LinkStatus = "Inactive"
+	// LinkStatusBlocked links are not checked, the robots.txt of their host disallows them.
+	LinkStatusBlocked LinkStatus = "BlockedByRobots"
+	// LinkStatusNotChecked links can't be fetched, like mailto: and javascript: links.
+	LinkStatusNotChecked LinkStatus = "NotChecked"
+)
+
+type LinkType string
+
+var (
+	LinkTypeHttp LinkType = "http"
+	// LinkTypeFragment links point to an anchor of the document itself, like `#section`.
+	LinkTypeFragment   LinkType = "fragment"
+	LinkTypeMailto     LinkType = "mailto"
+	LinkTypeTel        LinkType = "tel"
+	LinkTypeJavascript LinkType = "javascript"
+	// LinkTypeOther links use any other scheme, like data: or ftp:.
+	LinkTypeOther LinkType = "other"
 )
 
 type Link struct {
-	Name           string
+	Name string
+	// Url is the href of the link as written in the document, ResolvedUrl is the absolute url it points to.
 	Url            string
+	ResolvedUrl    string
+	Type           LinkType
 	IsInternal     bool
 	LinkStatus     LinkStatus
 	HttpStatusCode int
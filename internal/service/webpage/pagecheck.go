@@ -0,0 +1,168 @@
+package webpage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"golang.org/x/net/html"
+)
+
+// versionCheck sets the html version of the page and the doctype section from its doctype. Browsers
+// only honour a doctype which comes first, after the comments and the white space.
+type versionCheck struct {
+	docType  string
+	hasFound bool
+	started  bool
+}
+
+func newVersionCheck() Check {
+	return &versionCheck{}
+}
+
+func (c *versionCheck) Name() string {
+	return "doctype"
+}
+
+func (c *versionCheck) Token(t *Token) {
+	switch {
+	case c.hasFound || c.started:
+		return
+	case t.Type == html.DoctypeToken:
+		c.docType = t.Data
+		c.hasFound = true
+	case t.Type == html.TextToken:
+		c.started = strings.Trim(t.Data, " \t\n\f\r\ufeff") != ""
+	case t.Type != html.CommentToken:
+		c.started = true
+	}
+}
+
+func (c *versionCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	doctype := &model.Doctype{Name: model.DoctypeNone, RenderingMode: model.RenderingModeQuirks}
+	if c.hasFound {
+		var hasSystemId, forceQuirks bool
+		doctype, hasSystemId, forceQuirks = parseDoctype(c.docType)
+		doctype.Name = doctypeName(doctype, hasSystemId)
+		doctype.RenderingMode = renderingMode(doctype, hasSystemId, forceQuirks)
+	}
+
+	analysis.PageVersion = doctype.Name
+	analysis.Sections[c.Name()] = doctype
+
+	return nil
+}
+
+// titleCheck sets the title of the page, the <title> of the <head> element.
+type titleCheck struct {
+	title            string
+	foundTitleNode   bool
+	foundHeadElement bool
+	done             bool
+}
+
+func newTitleCheck() Check {
+	return &titleCheck{}
+}
+
+func (c *titleCheck) Name() string {
+	return "title"
+}
+
+func (c *titleCheck) Token(t *Token) {
+	if c.done {
+		return
+	}
+
+	switch t.Type {
+	case html.StartTagToken:
+		if t.Data == "head" {
+			c.foundHeadElement = true
+		}
+		if c.foundHeadElement && t.Data == "title" {
+			c.foundTitleNode = true
+		}
+	case html.EndTagToken:
+		if t.Data == "head" {
+			c.done = true
+		}
+	case html.TextToken:
+		if c.foundTitleNode {
+			c.title = strings.TrimSpace(t.Data)
+			c.done = true
+		}
+	}
+}
+
+func (c *titleCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	if !c.done {
+		return fmt.Errorf("head element not found in the document")
+	}
+
+	if !c.foundTitleNode {
+		return fmt.Errorf("title node not found in the document")
+	}
+	analysis.Title = c.title
+
+	return nil
+}
+
+// headingsCheck counts the headings of the page by level.
+type headingsCheck struct {
+	headings map[string]int
+}
+
+func newHeadingsCheck() Check {
+	return &headingsCheck{
+		headings: map[string]int{
+			"h1": 0,
+			"h2": 0,
+			"h3": 0,
+			"h4": 0,
+			"h5": 0,
+			"h6": 0,
+		},
+	}
+}
+
+func (c *headingsCheck) Name() string {
+	return "headings"
+}
+
+func (c *headingsCheck) Token(t *Token) {
+	if t.Type != html.StartTagToken {
+		return
+	}
+
+	if _, ok := c.headings[t.Data]; ok {
+		c.headings[t.Data]++
+	}
+}
+
+func (c *headingsCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	analysis.Headings = c.headings
+
+	return nil
+}
+
+// encodingCheck adds the character encoding the downloader detected to the sections.
+type encodingCheck struct{}
+
+func newEncodingCheck() Check {
+	return &encodingCheck{}
+}
+
+func (c *encodingCheck) Name() string {
+	return "encoding"
+}
+
+func (c *encodingCheck) Token(t *Token) {}
+
+func (c *encodingCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	if doc.Page.Encoding != nil {
+		analysis.Sections[c.Name()] = doc.Page.Encoding
+	}
+
+	return nil
+}
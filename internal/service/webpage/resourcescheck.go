@@ -0,0 +1,248 @@
+package webpage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"golang.org/x/net/html"
+)
+
+// preloadTypes are the resource types of the `as` values of <link rel=preload>.
+var preloadTypes = map[string]string{
+	"font":   model.ResourceTypeFont,
+	"style":  model.ResourceTypeStylesheet,
+	"script": model.ResourceTypeScript,
+	"image":  model.ResourceTypeImage,
+	"audio":  model.ResourceTypeMedia,
+	"video":  model.ResourceTypeMedia,
+}
+
+// resourcesCheck lists the scripts, stylesheets, fonts, images, icons, iframes and media the page
+// loads. Only the http resources are listed, the data: urls are part of the page.
+type resourcesCheck struct {
+	checker     LinkChecker
+	checkStatus bool
+
+	refs         resourceRefs
+	inlineScript bool
+	inlineStyle  bool
+	section      *model.ResourcesSection
+}
+
+// NewResourcesCheck creates the resource inventory check, with checkStatus the resources are
+// checked like the links of the page.
+func NewResourcesCheck(checker LinkChecker, checkStatus bool) NewCheck {
+	return func() Check {
+		return &resourcesCheck{
+			checker:     checker,
+			checkStatus: checkStatus,
+			section: &model.ResourcesSection{
+				Counts:            map[string]int{},
+				ThirdPartyDomains: []string{},
+				Resources:         []*model.Resource{},
+			},
+		}
+	}
+}
+
+func (c *resourcesCheck) Name() string {
+	return "resources"
+}
+
+func (c *resourcesCheck) Token(t *Token) {
+	c.refs.token(t)
+
+	switch {
+	case t.IsStartTag():
+		switch t.Data {
+		case "script":
+			if _, ok := t.AttrValue("src"); !ok && isJavascript(t) {
+				c.inlineScript = t.Type == html.StartTagToken
+			}
+		case "style":
+			c.inlineStyle = t.Type == html.StartTagToken
+		}
+	case t.Type == html.EndTagToken:
+		switch t.Data {
+		case "script":
+			c.inlineScript = false
+		case "style":
+			c.inlineStyle = false
+		}
+	case t.Type == html.TextToken && strings.TrimSpace(t.Data) != "":
+		// an inline block is counted once, on its first text
+		if c.inlineScript {
+			c.section.InlineScriptCount++
+			c.inlineScript = false
+		}
+		if c.inlineStyle {
+			c.section.InlineStyleCount++
+			c.inlineStyle = false
+		}
+	}
+}
+
+func (c *resourcesCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	seen := map[string]bool{}
+	domains := map[string]bool{}
+	for _, r := range c.refs.list {
+		resolved, err := doc.Resolve(r.url)
+		if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+			continue
+		}
+
+		// a resource referenced many times is loaded once
+		key := r.typ + " " + resolved.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		resource := &model.Resource{
+			Type:        r.typ,
+			Url:         r.url,
+			ResolvedUrl: resolved.String(),
+			IsInternal:  isInternalLink(doc.Url, resolved),
+			Line:        r.line,
+		}
+		c.section.Resources = append(c.section.Resources, resource)
+		c.section.Counts[r.typ]++
+		if resource.IsInternal {
+			c.section.InternalCount++
+		} else {
+			c.section.ExternalCount++
+			domains[strings.ToLower(resolved.Hostname())] = true
+		}
+	}
+
+	for d := range domains {
+		c.section.ThirdPartyDomains = append(c.section.ThirdPartyDomains, d)
+	}
+	sort.Strings(c.section.ThirdPartyDomains)
+
+	if c.checkStatus {
+		c.check(ctx)
+	}
+	analysis.Sections[c.Name()] = c.section
+
+	return nil
+}
+
+// check checks the resources concurrently, the same way as the links.
+func (c *resourcesCheck) check(ctx context.Context) {
+	wg := sync.WaitGroup{}
+	for _, r := range c.section.Resources {
+		wg.Add(1)
+		go func(r *model.Resource) {
+			defer wg.Done()
+			link := strings.SplitN(r.ResolvedUrl, "#", 2)[0]
+			r.Status, r.HttpStatusCode = c.checker.Check(ctx, link)
+		}(r)
+	}
+	wg.Wait()
+
+	for _, r := range c.section.Resources {
+		if r.Status == model.LinkStatusInactive {
+			c.section.InactiveCount++
+		}
+	}
+}
+
+// isJavascript reports whether the script element holds javascript, data blocks like JSON-LD don't.
+func isJavascript(t *Token) bool {
+	typ, _ := t.AttrValue("type")
+	switch strings.ToLower(strings.TrimSpace(typ)) {
+	case "", "module", "text/javascript", "application/javascript", "text/ecmascript", "application/ecmascript":
+		return true
+	default:
+		return false
+	}
+}
+
+// resourceRefs collects the references of the resources the page loads, in document order.
+type resourceRefs struct {
+	list      []*resourceRef
+	inPicture bool
+}
+
+type resourceRef struct {
+	typ  string
+	url  string
+	line int
+}
+
+func (r *resourceRefs) token(t *Token) {
+	if t.Type == html.EndTagToken && t.Data == "picture" {
+		r.inPicture = false
+	}
+	if !t.IsStartTag() {
+		return
+	}
+
+	switch t.Data {
+	case "script":
+		r.addAttr(model.ResourceTypeScript, "src", t)
+	case "link":
+		href, _ := t.AttrValue("href")
+		rels, _ := t.AttrValue("rel")
+		for _, rel := range strings.Fields(strings.ToLower(rels)) {
+			switch rel {
+			case "stylesheet":
+				r.add(model.ResourceTypeStylesheet, href, t)
+			case "icon", "apple-touch-icon":
+				r.add(model.ResourceTypeIcon, href, t)
+			case "preload", "modulepreload":
+				as, _ := t.AttrValue("as")
+				typ, ok := preloadTypes[strings.ToLower(strings.TrimSpace(as))]
+				if rel == "modulepreload" {
+					typ, ok = model.ResourceTypeScript, true
+				}
+				if !ok {
+					typ = model.ResourceTypePreload
+				}
+				r.add(typ, href, t)
+			}
+		}
+	case "img":
+		r.addAttr(model.ResourceTypeImage, "src", t)
+		r.addSrcset(model.ResourceTypeImage, t)
+	case "iframe":
+		r.addAttr(model.ResourceTypeIframe, "src", t)
+	case "picture":
+		r.inPicture = t.Type == html.StartTagToken
+	case "video", "audio":
+		r.addAttr(model.ResourceTypeMedia, "src", t)
+		r.addAttr(model.ResourceTypeImage, "poster", t)
+	case "source":
+		if r.inPicture {
+			r.addSrcset(model.ResourceTypeImage, t)
+		} else {
+			r.addAttr(model.ResourceTypeMedia, "src", t)
+		}
+	}
+}
+
+func (r *resourceRefs) addAttr(typ, key string, t *Token) {
+	if v, ok := t.AttrValue(key); ok {
+		r.add(typ, v, t)
+	}
+}
+
+// addSrcset adds the image candidates of the srcset, `url [descriptor], ...`.
+func (r *resourceRefs) addSrcset(typ string, t *Token) {
+	srcset, _ := t.AttrValue("srcset")
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			r.add(typ, fields[0], t)
+		}
+	}
+}
+
+func (r *resourceRefs) add(typ, ref string, t *Token) {
+	if strings.TrimSpace(ref) != "" {
+		r.list = append(r.list, &resourceRef{typ: typ, url: ref, line: t.Line})
+	}
+}
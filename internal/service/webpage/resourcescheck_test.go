@@ -0,0 +1,73 @@
+package webpage
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	mc "github.com/DiLRandI/web-analyser/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const resourcesPage = `<html><head>
+<link rel="stylesheet" href="/css/site.css">
+<link rel="preload" as="font" href="https://fonts.gstatic.com/s/roboto.woff2" crossorigin>
+<link rel="icon" href="/favicon.ico">
+<script src="https://cdn.test.com/lib.js"></script>
+<script>window.ready = true;</script>
+<script type="application/ld+json">{"@type": "Thing"}</script>
+<style>body { margin: 0; }</style>
+</head>
+<body>
+<img src="/logo.png" srcset="/logo.png 1x, /logo@2x.png 2x">
+<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=">
+<picture><source srcset="/hero.webp"><img src="/hero.jpg"></picture>
+<video src="/intro.mp4" poster="/intro.jpg"><source src="/intro.webm"></video>
+<iframe src="https://www.youtube.com/embed/abc"></iframe>
+</body></html>`
+
+func Test_resources_check_should_list_the_resources_of_the_page(t *testing.T) {
+	client := new(mc.WebClientMock)
+	analysis, err := runCheck(NewResourcesCheck(NewLinkChecker(client, 0), false)(), "https://www.test.com/",
+		resourcesPage)
+	require.NoError(t, err)
+
+	section, ok := analysis.Sections["resources"].(*model.ResourcesSection)
+	require.True(t, ok)
+	assert.Equal(t, map[string]int{
+		"stylesheet": 1, "font": 1, "icon": 1, "script": 1, "image": 5, "media": 2, "iframe": 1,
+	}, section.Counts)
+	assert.Equal(t, 9, section.InternalCount)
+	assert.Equal(t, 3, section.ExternalCount)
+	assert.Equal(t, 1, section.InlineScriptCount)
+	assert.Equal(t, 1, section.InlineStyleCount)
+	assert.Equal(t, []string{"cdn.test.com", "fonts.gstatic.com", "www.youtube.com"}, section.ThirdPartyDomains)
+	assert.Equal(t, &model.Resource{
+		Type:        model.ResourceTypeImage,
+		Url:         "/logo@2x.png",
+		ResolvedUrl: "https://www.test.com/logo@2x.png",
+		IsInternal:  true,
+		Line:        11,
+	}, section.Resources[5])
+	assert.Empty(t, client.Calls)
+}
+
+func Test_resources_check_should_check_the_resources_when_enabled(t *testing.T) {
+	client := new(mc.WebClientMock)
+	client.On("Do", http.MethodHead, "https://www.test.com/app.js").
+		Return(&http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil)
+	client.On("Do", http.MethodHead, "https://www.test.com/missing.css").
+		Return(&http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody}, nil)
+
+	analysis, err := runCheck(NewResourcesCheck(NewLinkChecker(client, 0), true)(), "https://www.test.com/",
+		`<link rel="stylesheet" href="missing.css"><script src="app.js#v2"></script>`)
+	require.NoError(t, err)
+
+	section := analysis.Sections["resources"].(*model.ResourcesSection)
+	assert.Equal(t, model.LinkStatusInactive, section.Resources[0].Status)
+	assert.Equal(t, http.StatusNotFound, section.Resources[0].HttpStatusCode)
+	assert.Equal(t, model.LinkStatusActive, section.Resources[1].Status)
+	assert.Equal(t, 1, section.InactiveCount)
+	client.AssertExpectations(t)
+}
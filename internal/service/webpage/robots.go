@@ -0,0 +1,323 @@
+package webpage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	BlockedByRobotsErr = errors.New("blocked by robots.txt")
+)
+
+const (
+	robotsCacheTtl = 24 * time.Hour
+	// maxRobotsSize is the part of a robots.txt which is parsed, as recommended by RFC 9309.
+	maxRobotsSize = 500 << 10
+)
+
+// robotsClient is a WebClient which honours the robots.txt of the hosts it fetches from. Disallowed
+// requests fail with BlockedByRobotsErr and requests to a host with a Crawl-delay are spaced out.
+type robotsClient struct {
+	client WebClient
+	// agent is the product token of the User-Agent matched against the robots.txt groups.
+	agent    string
+	maxDelay time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+type robotsHost struct {
+	// loaded is closed once rules and expires are set.
+	loaded  chan struct{}
+	rules   *robotsGroup
+	expires time.Time
+	// next is the earliest time of the next request to the host when it has a Crawl-delay.
+	next time.Time
+}
+
+// NewRobotsClient wraps the client with robots.txt compliance for the userAgent, the robots.txt of
+// a host is cached for a day. Crawl delays are capped at maxDelay, 0 means no cap.
+func NewRobotsClient(client WebClient, userAgent string, maxDelay time.Duration) WebClient {
+	agent, _, _ := strings.Cut(userAgent, "/")
+	return &robotsClient{
+		client:   client,
+		agent:    strings.ToLower(strings.TrimSpace(agent)),
+		maxDelay: maxDelay,
+		hosts:    map[string]*robotsHost{},
+	}
+}
+
+func (c *robotsClient) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+func (c *robotsClient) Do(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return c.client.Do(req)
+	}
+
+	rules, err := c.rules(req.Context(), req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rules.allowed(req.URL.EscapedPath(), req.URL.RawQuery) {
+		return nil, fmt.Errorf("%w, %s", BlockedByRobotsErr, req.URL)
+	}
+
+	if err := c.wait(req.Context(), req.URL, rules.crawlDelay); err != nil {
+		return nil, err
+	}
+
+	return c.client.Do(req)
+}
+
+// rules returns the robots.txt group of the host matching the agent, concurrent requests to a host
+// share a single robots.txt download.
+func (c *robotsClient) rules(ctx context.Context, u *url.URL) (*robotsGroup, error) {
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	c.mu.Lock()
+	h, ok := c.hosts[key]
+	if ok {
+		select {
+		case <-h.loaded:
+			if time.Now().After(h.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+
+	if !ok {
+		next := time.Time{}
+		if h != nil {
+			next = h.next
+		}
+		h = &robotsHost{loaded: make(chan struct{}), next: next}
+		c.hosts[key] = h
+		c.mu.Unlock()
+
+		h.rules = c.fetch(ctx, key)
+		h.expires = time.Now().Add(robotsCacheTtl)
+		if ctx.Err() != nil {
+			// an interrupted download is not cached, the next request and the waiting ones download it again
+			h.rules = nil
+			h.expires = time.Time{}
+			close(h.loaded)
+			return nil, ctx.Err()
+		}
+		close(h.loaded)
+		return h.rules, nil
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-h.loaded:
+		if h.rules == nil {
+			return c.rules(ctx, u)
+		}
+		return h.rules, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch downloads and parses the robots.txt following RFC 9309: a missing file (4xx) allows
+// everything and a server error (5xx) disallows everything. A host which can't be reached is
+// allowed, the request itself reports the failure.
+func (c *robotsClient) fetch(ctx context.Context, host string) *robotsGroup {
+	robotsUrl := host + "/robots.txt"
+	res, err := c.client.Get(ctx, robotsUrl)
+	if err != nil {
+		logrus.Warnf("Unable to download %q, %v", robotsUrl, err)
+		return &robotsGroup{}
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logrus.Warnf("Error while closing the response body, %v", err)
+		}
+	}()
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		logrus.Warnf("%q responded with %d, disallowing the host", robotsUrl, res.StatusCode)
+		return &robotsGroup{rules: []robotsRule{{pattern: "/", re: compileRobotsPattern("/")}}}
+	case res.StatusCode >= http.StatusBadRequest:
+		return &robotsGroup{}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(res.Body, maxRobotsSize))
+	if err != nil && !errors.Is(err, ResponseTooLargeErr) {
+		logrus.Warnf("Unable to read %q, %v", robotsUrl, err)
+		return &robotsGroup{}
+	}
+
+	return parseRobots(content, c.agent)
+}
+
+// wait blocks until the Crawl-delay of the host since the previous request passed.
+func (c *robotsClient) wait(ctx context.Context, u *url.URL, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	if c.maxDelay > 0 && delay > c.maxDelay {
+		delay = c.maxDelay
+	}
+
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+	c.mu.Lock()
+	h := c.hosts[key]
+	at := time.Now()
+	if h.next.After(at) {
+		at = h.next
+	}
+	h.next = at.Add(delay)
+	c.mu.Unlock()
+
+	t := time.NewTimer(time.Until(at))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// robotsGroup is the set of rules of a robots.txt which applies to an agent.
+type robotsGroup struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+// allowed applies the most specific (longest) matching rule, allow wins a tie.
+func (g *robotsGroup) allowed(path, query string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if query != "" {
+		path += "?" + query
+	}
+
+	allow := true
+	matched := -1
+	for _, r := range g.rules {
+		if len(r.pattern) < matched || !r.re.MatchString(path) {
+			continue
+		}
+
+		if len(r.pattern) > matched || r.allow {
+			allow = r.allow
+		}
+		matched = len(r.pattern)
+	}
+
+	return allow
+}
+
+// compileRobotsPattern turns a rule pattern into a regexp, `*` matches any sequence of characters
+// and a trailing `$` anchors the pattern at the end of the path.
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	expr := strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, `.*`)
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile("^" + expr)
+}
+
+// parseRobots returns the rules of the group naming the agent, the `*` group applies when no group
+// names the agent.
+func parseRobots(content []byte, agent string) *robotsGroup {
+	groups := map[string]*robotsGroup{}
+	current := []*robotsGroup{}
+	inAgents := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share the rules which follow them
+			if !inAgents {
+				current = []*robotsGroup{}
+			}
+			inAgents = true
+
+			name := strings.ToLower(value)
+			g, ok := groups[name]
+			if !ok {
+				g = &robotsGroup{}
+				groups[name] = g
+			}
+			current = append(current, g)
+		case "allow", "disallow":
+			inAgents = false
+			// an empty disallow allows everything, it adds no rule
+			if value == "" {
+				continue
+			}
+			for _, g := range current {
+				g.rules = append(g.rules, robotsRule{
+					allow: key == "allow", pattern: value, re: compileRobotsPattern(value),
+				})
+			}
+		case "crawl-delay":
+			inAgents = false
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 {
+				continue
+			}
+			for _, g := range current {
+				g.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	if g, ok := groups[agent]; ok {
+		return g
+	}
+
+	if g, ok := groups["*"]; ok {
+		return g
+	}
+
+	return &robotsGroup{}
+}
@@ -0,0 +1,200 @@
+package webpage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parse_robots_should_pick_the_group_of_the_agent(t *testing.T) {
+	content := `# comment
+User-agent: *
+Disallow: /
+
+User-agent: other-bot
+User-agent: Web-Analyser
+Disallow: /private # trailing comment
+Allow: /private/public
+Crawl-delay: 1.5
+`
+
+	group := parseRobots([]byte(content), "web-analyser")
+
+	assert.Len(t, group.rules, 2)
+	assert.Equal(t, 1500*time.Millisecond, group.crawlDelay)
+	assert.True(t, group.allowed("/", ""))
+	assert.False(t, group.allowed("/private/page", ""))
+	assert.True(t, group.allowed("/private/public/page", ""))
+
+	group = parseRobots([]byte(content), "unknown")
+	assert.False(t, group.allowed("/", ""))
+
+	group = parseRobots([]byte(""), "web-analyser")
+	assert.True(t, group.allowed("/", ""))
+}
+
+func Test_robots_group_allowed(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		rules   map[string]bool
+		path    string
+		query   string
+		allowed bool
+	}{
+		{desc: "no rules allow everything", path: "/page", allowed: true},
+		{desc: "prefix disallows", rules: map[string]bool{"/admin": false}, path: "/admin/users", allowed: false},
+		{desc: "longest match wins", rules: map[string]bool{"/a": false, "/a/b": true}, path: "/a/b/c", allowed: true},
+		{desc: "allow wins a tie", rules: map[string]bool{"/ab": false, "/a*": true}, path: "/ab", allowed: true},
+		{desc: "wildcard matches", rules: map[string]bool{"/*.pdf": false}, path: "/docs/file.pdf", allowed: false},
+		{desc: "end anchor", rules: map[string]bool{"/*.pdf$": false}, path: "/file.pdf.html", allowed: true},
+		{desc: "query is matched", rules: map[string]bool{"/*?session=": false}, path: "/page", query: "session=1",
+			allowed: false},
+		{desc: "regexp characters are literals", rules: map[string]bool{"/a.b": false}, path: "/axb", allowed: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			group := &robotsGroup{}
+			for pattern, allow := range tc.rules {
+				group.rules = append(group.rules, robotsRule{
+					allow: allow, pattern: pattern, re: compileRobotsPattern(pattern),
+				})
+			}
+
+			assert.Equal(t, tc.allowed, group.allowed(tc.path, tc.query))
+		})
+	}
+}
+
+func Test_robots_client_should_block_disallowed_urls_and_cache_the_robots(t *testing.T) {
+	robotsRequests := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&robotsRequests, 1)
+			_, _ = io.WriteString(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewRobotsClient(client, "web-analyser/test", 0)
+
+	_, err = sut.Get(context.Background(), srv.URL+"/private/page")
+	assert.ErrorIs(t, err, BlockedByRobotsErr)
+
+	res, err := sut.Get(context.Background(), srv.URL+"/public")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&robotsRequests))
+}
+
+func Test_robots_client_should_follow_the_robots_status_code(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		status  int
+		allowed bool
+	}{
+		{desc: "missing robots allow everything", status: http.StatusNotFound, allowed: true},
+		{desc: "robots server error disallow everything", status: http.StatusServiceUnavailable, allowed: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					w.WriteHeader(tc.status)
+				}
+			}))
+			defer srv.Close()
+
+			client, err := NewWebClient(&ClientConfig{})
+			require.NoError(t, err)
+			sut := NewRobotsClient(client, "web-analyser/test", 0)
+
+			res, err := sut.Get(context.Background(), srv.URL+"/page")
+			if tc.allowed {
+				require.NoError(t, err)
+				_ = res.Body.Close()
+			} else {
+				assert.ErrorIs(t, err, BlockedByRobotsErr)
+			}
+		})
+	}
+}
+
+func Test_robots_client_should_space_out_requests_by_the_crawl_delay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = io.WriteString(w, "User-agent: *\nCrawl-delay: 10\n")
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	// the crawl delay of the robots is capped
+	sut := NewRobotsClient(client, "web-analyser/test", 100*time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		res, err := sut.Get(context.Background(), srv.URL+"/page")
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func Test_robots_client_should_download_the_robots_again_when_the_first_request_is_cancelled(t *testing.T) {
+	robotsRequests := int32(0)
+	downloading := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			if atomic.AddInt32(&robotsRequests, 1) == 1 {
+				// the first download hangs until its request is cancelled
+				close(downloading)
+				<-r.Context().Done()
+				return
+			}
+			_, _ = io.WriteString(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	client, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	sut := NewRobotsClient(client, "web-analyser/test", 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := sut.Get(ctx, srv.URL+"/private/first")
+		firstErr <- err
+	}()
+	<-downloading
+
+	secondErr := make(chan error, 1)
+	go func() {
+		_, err := sut.Get(context.Background(), srv.URL+"/private/second")
+		secondErr <- err
+	}()
+	// the second request waits for the download of the first one
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+	assert.ErrorIs(t, <-secondErr, BlockedByRobotsErr)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&robotsRequests))
+}
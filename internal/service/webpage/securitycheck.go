@@ -0,0 +1,406 @@
+package webpage
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+)
+
+const (
+	// minHstsMaxAge is the HSTS max-age of six months, shorter policies expire between the visits.
+	minHstsMaxAge = 15768000
+	// certificateExpiryWarning is the number of days before the expiry of the certificate it is reported.
+	certificateExpiryWarning = 30
+
+	// failPenalty and securityWarningPenalty are the points a result takes off the security score.
+	failPenalty            = 20
+	securityWarningPenalty = 5
+)
+
+// securityCheck grades the security headers and the cookies of the response and the TLS connection
+// the page was served over. A Content-Security-Policy <meta> counts when the header is missing.
+type securityCheck struct {
+	metaCsp string
+}
+
+func newSecurityCheck() Check {
+	return &securityCheck{}
+}
+
+func (c *securityCheck) Name() string {
+	return "security"
+}
+
+func (c *securityCheck) Token(t *Token) {
+	if !t.IsStartTag() || t.Data != "meta" || c.metaCsp != "" {
+		return
+	}
+
+	if equiv, _ := t.AttrValue("http-equiv"); strings.EqualFold(strings.TrimSpace(equiv), "content-security-policy") {
+		c.metaCsp, _ = t.AttrValue("content")
+	}
+}
+
+func (c *securityCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	headers := doc.Page.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	section := &model.SecuritySection{
+		Https:   strings.EqualFold(doc.Url.Scheme, "https"),
+		Cookies: []*model.CookieResult{},
+	}
+
+	csp := cspDirectives(strings.Join(headers.Values("Content-Security-Policy"), ";"))
+	section.Headers = []*model.HeaderResult{
+		c.contentSecurityPolicy(headers),
+		strictTransportSecurity(headers, section.Https),
+		frameOptions(headers, csp),
+		contentTypeOptions(headers),
+		referrerPolicy(headers),
+		permissionsPolicy(headers),
+	}
+
+	response := &http.Response{Header: headers}
+	for _, cookie := range response.Cookies() {
+		section.Cookies = append(section.Cookies, cookieResult(cookie))
+	}
+
+	if doc.Page.TLS != nil {
+		section.TLS = tlsResult(doc.Page.TLS)
+	}
+
+	results := []model.Result{}
+	if !section.Https {
+		results = append(results, model.ResultFail)
+	}
+	for _, h := range section.Headers {
+		results = append(results, h.Result)
+	}
+	for _, cookie := range section.Cookies {
+		results = append(results, cookie.Result)
+	}
+	if section.TLS != nil {
+		results = append(results, section.TLS.Result)
+	}
+
+	section.Score = securityScore(results)
+	section.Grade = securityGrade(section.Score)
+	analysis.Sections[c.Name()] = section
+
+	return nil
+}
+
+func (c *securityCheck) contentSecurityPolicy(headers http.Header) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "Content-Security-Policy"}
+	r.Value = strings.Join(headers.Values(r.Name), "; ")
+	note := ""
+	if r.Value == "" && c.metaCsp != "" {
+		r.Value = c.metaCsp
+		note = "the policy is set by a meta tag, "
+	}
+
+	if r.Value == "" {
+		return grade(r, model.ResultFail, "the page has no Content-Security-Policy")
+	}
+
+	directives := cspDirectives(r.Value)
+	sources, ok := directives["script-src"]
+	if !ok {
+		sources, ok = directives["default-src"]
+	}
+	if !ok {
+		return grade(r, model.ResultWarning, note+"the policy doesn't restrict the scripts, "+
+			"it has no script-src or default-src")
+	}
+
+	problems := []string{}
+	if hasSource(sources, "'unsafe-inline'") && !hasNonceOrHash(sources) {
+		problems = append(problems, "it allows inline scripts")
+	}
+	if hasSource(sources, "'unsafe-eval'") {
+		problems = append(problems, "it allows eval")
+	}
+	if hasSource(sources, "*") || hasSource(sources, "http:") || hasSource(sources, "https:") ||
+		hasSource(sources, "data:") {
+		problems = append(problems, "it allows scripts from any host")
+	}
+	if len(problems) > 0 {
+		return grade(r, model.ResultWarning, note+strings.Join(problems, ", "))
+	}
+
+	return grade(r, model.ResultPass, note+"the policy restricts the scripts")
+}
+
+func strictTransportSecurity(headers http.Header, https bool) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "Strict-Transport-Security"}
+	r.Value = headers.Get(r.Name)
+	if !https {
+		return grade(r, model.ResultFail, "the page is not served over https")
+	}
+
+	if r.Value == "" {
+		return grade(r, model.ResultFail, "the page has no Strict-Transport-Security")
+	}
+
+	maxAge := -1
+	for _, d := range strings.Split(r.Value, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(k, "max-age") {
+			if n, err := strconv.Atoi(strings.Trim(v, `"`)); err == nil {
+				maxAge = n
+			}
+		}
+	}
+
+	switch {
+	case maxAge < 0:
+		return grade(r, model.ResultFail, "the max-age is missing or invalid")
+	case maxAge == 0:
+		return grade(r, model.ResultFail, "max-age=0 disables the policy")
+	case maxAge < minHstsMaxAge:
+		return grade(r, model.ResultWarning, fmt.Sprintf("the max-age is shorter than %d seconds", minHstsMaxAge))
+	}
+
+	return grade(r, model.ResultPass, "https is enforced")
+}
+
+// frameOptions grades the framing protection, the frame-ancestors directive replaces X-Frame-Options.
+func frameOptions(headers http.Header, csp map[string][]string) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "X-Frame-Options"}
+	r.Value = headers.Get(r.Name)
+	if ancestors, ok := csp["frame-ancestors"]; ok {
+		r.Value = "frame-ancestors " + strings.Join(ancestors, " ")
+		return grade(r, model.ResultPass, "framing is restricted by the Content-Security-Policy frame-ancestors")
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(r.Value)) {
+	case "":
+		return grade(r, model.ResultFail, "the page can be framed by any site, it has no X-Frame-Options "+
+			"or frame-ancestors")
+	case "DENY", "SAMEORIGIN":
+		return grade(r, model.ResultPass, "framing is restricted")
+	default:
+		return grade(r, model.ResultFail, "the value is not DENY or SAMEORIGIN, browsers ignore it")
+	}
+}
+
+func contentTypeOptions(headers http.Header) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "X-Content-Type-Options"}
+	r.Value = headers.Get(r.Name)
+	switch {
+	case r.Value == "":
+		return grade(r, model.ResultFail, "the page has no X-Content-Type-Options")
+	case !strings.EqualFold(strings.TrimSpace(r.Value), "nosniff"):
+		return grade(r, model.ResultFail, "the only valid value is nosniff")
+	}
+
+	return grade(r, model.ResultPass, "content sniffing is disabled")
+}
+
+func referrerPolicy(headers http.Header) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "Referrer-Policy"}
+	r.Value = strings.Join(headers.Values(r.Name), ", ")
+	if r.Value == "" {
+		return grade(r, model.ResultWarning, "the page has no Referrer-Policy, "+
+			"browsers default to strict-origin-when-cross-origin")
+	}
+
+	// the last policy the browser understands applies
+	policy := ""
+	for _, p := range strings.Split(r.Value, ",") {
+		switch p = strings.ToLower(strings.TrimSpace(p)); p {
+		case "no-referrer", "no-referrer-when-downgrade", "origin", "origin-when-cross-origin",
+			"same-origin", "strict-origin", "strict-origin-when-cross-origin", "unsafe-url":
+			policy = p
+		}
+	}
+
+	switch policy {
+	case "":
+		return grade(r, model.ResultWarning, "the policy is not valid")
+	case "unsafe-url":
+		return grade(r, model.ResultFail, "the full url is sent to every site, https urls included")
+	case "no-referrer-when-downgrade":
+		return grade(r, model.ResultWarning, "the full url is sent to the other sites")
+	}
+
+	return grade(r, model.ResultPass, "the referrer is restricted")
+}
+
+func permissionsPolicy(headers http.Header) *model.HeaderResult {
+	r := &model.HeaderResult{Name: "Permissions-Policy"}
+	r.Value = headers.Get(r.Name)
+	if r.Value == "" {
+		return grade(r, model.ResultWarning, "the page has no Permissions-Policy")
+	}
+
+	return grade(r, model.ResultPass, "the browser features are restricted")
+}
+
+func cookieResult(cookie *http.Cookie) *model.CookieResult {
+	r := &model.CookieResult{
+		Name:     cookie.Name,
+		Secure:   cookie.Secure,
+		HttpOnly: cookie.HttpOnly,
+		Result:   model.ResultPass,
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		r.SameSite = "Lax"
+	case http.SameSiteStrictMode:
+		r.SameSite = "Strict"
+	case http.SameSiteNoneMode:
+		r.SameSite = "None"
+	}
+
+	problems := []string{}
+	report := func(result model.Result, problem string) {
+		problems = append(problems, problem)
+		if r.Result != model.ResultFail {
+			r.Result = result
+		}
+	}
+
+	if !cookie.Secure {
+		report(model.ResultFail, "it has no Secure flag, it is sent over http")
+	}
+	if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+		report(model.ResultFail, "browsers reject SameSite=None without Secure")
+	}
+	if !cookie.HttpOnly {
+		report(model.ResultWarning, "it has no HttpOnly flag, scripts can read it")
+	}
+	if r.SameSite == "" {
+		report(model.ResultWarning, "it has no SameSite, browsers default to Lax")
+	}
+
+	r.Message = strings.Join(problems, ", ")
+	if len(problems) == 0 {
+		r.Message = "the cookie is protected"
+	}
+
+	return r
+}
+
+func tlsResult(t *model.TLS) *model.TLSResult {
+	r := &model.TLSResult{
+		Version:            t.Version,
+		CipherSuite:        t.CipherSuite,
+		CertificateSubject: t.CertificateSubject,
+		CertificateIssuer:  t.CertificateIssuer,
+		CertificateExpires: t.CertificateExpires,
+		DaysToExpiry:       int(time.Until(t.CertificateExpires).Hours() / 24),
+		Result:             model.ResultPass,
+		Message:            "the connection is secure",
+	}
+
+	insecure := false
+	for _, s := range tls.InsecureCipherSuites() {
+		insecure = insecure || s.Name == t.CipherSuite
+	}
+
+	switch {
+	case t.Version == "TLS 1.0" || t.Version == "TLS 1.1":
+		r.Result, r.Message = model.ResultFail, t.Version+" is deprecated, TLS 1.2 is the minimum"
+	case insecure:
+		r.Result, r.Message = model.ResultFail, "the cipher suite is insecure"
+	case t.CertificateExpires.IsZero():
+		// the server sent no certificate, the connection could not be established without one
+	case time.Now().After(t.CertificateExpires):
+		r.Result, r.Message = model.ResultFail, "the certificate expired"
+	case r.DaysToExpiry < certificateExpiryWarning:
+		r.Result, r.Message = model.ResultWarning, fmt.Sprintf("the certificate expires in %d days", r.DaysToExpiry)
+	}
+
+	return r
+}
+
+func grade(r *model.HeaderResult, result model.Result, message string) *model.HeaderResult {
+	r.Result = result
+	r.Message = message
+
+	return r
+}
+
+// cspDirectives parses the policy into its directives, the first occurrence of a directive applies.
+func cspDirectives(policy string) map[string][]string {
+	directives := map[string][]string{}
+	for _, d := range strings.Split(policy, ";") {
+		fields := strings.Fields(d)
+		if len(fields) == 0 {
+			continue
+		}
+
+		name := strings.ToLower(fields[0])
+		if _, ok := directives[name]; !ok {
+			directives[name] = fields[1:]
+		}
+	}
+
+	return directives
+}
+
+func hasSource(sources []string, source string) bool {
+	for _, s := range sources {
+		if strings.EqualFold(s, source) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasNonceOrHash reports whether the sources allow scripts by nonce or hash, browsers ignore
+// 'unsafe-inline' then.
+func hasNonceOrHash(sources []string) bool {
+	for _, s := range sources {
+		s = strings.ToLower(s)
+		if strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") ||
+			strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func securityScore(results []model.Result) int {
+	score := 100
+	for _, r := range results {
+		switch r {
+		case model.ResultFail:
+			score -= failPenalty
+		case model.ResultWarning:
+			score -= securityWarningPenalty
+		}
+	}
+
+	if score < 0 {
+		return 0
+	}
+
+	return score
+}
+
+func securityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
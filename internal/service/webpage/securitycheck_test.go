@@ -0,0 +1,168 @@
+package webpage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func headerResults(section *model.SecuritySection) map[string]model.Result {
+	results := map[string]model.Result{}
+	for _, h := range section.Headers {
+		results[h.Name] = h.Result
+	}
+
+	return results
+}
+
+func Test_security_check_should_pass_a_hardened_page(t *testing.T) {
+	analysis, err := runChecks(&model.DownloadedWebpage{
+		Url: "https://www.test.com",
+		Headers: http.Header{
+			"Content-Security-Policy":   {"default-src 'self'; script-src 'self' 'nonce-abc' 'unsafe-inline'"},
+			"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+			"X-Frame-Options":           {"DENY"},
+			"X-Content-Type-Options":    {"nosniff"},
+			"Referrer-Policy":           {"no-referrer, strict-origin-when-cross-origin"},
+			"Permissions-Policy":        {"camera=()"},
+			"Set-Cookie":                {"session=1; Secure; HttpOnly; SameSite=Strict"},
+		},
+		TLS: &model.TLS{
+			Version:            "TLS 1.3",
+			CipherSuite:        "TLS_AES_128_GCM_SHA256",
+			CertificateExpires: time.Now().Add(90 * 24 * time.Hour),
+		},
+		Content: []byte(`<html><head></head><body></body></html>`),
+	}, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+
+	assert.True(t, section.Https)
+	assert.Equal(t, 100, section.Score)
+	assert.Equal(t, "A", section.Grade)
+	for _, h := range section.Headers {
+		assert.Equal(t, model.ResultPass, h.Result, h.Name)
+	}
+	require.Len(t, section.Cookies, 1)
+	assert.Equal(t, "Strict", section.Cookies[0].SameSite)
+	assert.Equal(t, model.ResultPass, section.Cookies[0].Result)
+	require.NotNil(t, section.TLS)
+	assert.Equal(t, model.ResultPass, section.TLS.Result)
+}
+
+func Test_security_check_should_fail_a_plain_http_page(t *testing.T) {
+	analysis, err := runChecks(&model.DownloadedWebpage{
+		Url: "http://www.test.com",
+		Headers: http.Header{
+			"Set-Cookie": {"session=1"},
+		},
+		Content: []byte(`<html><head></head><body></body></html>`),
+	}, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+
+	assert.False(t, section.Https)
+	assert.Nil(t, section.TLS)
+	assert.Equal(t, "F", section.Grade)
+	assert.Equal(t, map[string]model.Result{
+		"Content-Security-Policy":   model.ResultFail,
+		"Strict-Transport-Security": model.ResultFail,
+		"X-Frame-Options":           model.ResultFail,
+		"X-Content-Type-Options":    model.ResultFail,
+		"Referrer-Policy":           model.ResultWarning,
+		"Permissions-Policy":        model.ResultWarning,
+	}, headerResults(section))
+	require.Len(t, section.Cookies, 1)
+	assert.Equal(t, model.ResultFail, section.Cookies[0].Result)
+	assert.Contains(t, section.Cookies[0].Message, "Secure")
+}
+
+func Test_security_check_should_grade_the_weak_policies(t *testing.T) {
+	analysis, err := runChecks(&model.DownloadedWebpage{
+		Url: "https://www.test.com",
+		Headers: http.Header{
+			"Strict-Transport-Security": {"max-age=3600"},
+			"Content-Security-Policy":   {"frame-ancestors 'none'"},
+			"X-Content-Type-Options":    {"sniff"},
+			"Referrer-Policy":           {"unsafe-url"},
+		},
+		TLS: &model.TLS{
+			Version:            "TLS 1.2",
+			CertificateExpires: time.Now().Add(10 * 24 * time.Hour),
+		},
+		Content: []byte(`<html><head>
+<meta http-equiv="Content-Security-Policy" content="script-src * 'unsafe-eval'">
+</head><body></body></html>`),
+	}, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+
+	assert.Equal(t, map[string]model.Result{
+		"Content-Security-Policy":   model.ResultWarning,
+		"Strict-Transport-Security": model.ResultWarning,
+		"X-Frame-Options":           model.ResultPass,
+		"X-Content-Type-Options":    model.ResultFail,
+		"Referrer-Policy":           model.ResultFail,
+		"Permissions-Policy":        model.ResultWarning,
+	}, headerResults(section))
+	assert.Equal(t, model.ResultWarning, section.TLS.Result)
+	assert.Equal(t, 9, section.TLS.DaysToExpiry)
+}
+
+func Test_security_check_should_use_the_meta_policy_without_the_header(t *testing.T) {
+	analysis, err := runChecks(&model.DownloadedWebpage{
+		Url: "https://www.test.com",
+		Content: []byte(`<html><head>
+<meta http-equiv="content-security-policy" content="script-src 'self' 'unsafe-inline'">
+</head><body></body></html>`),
+	}, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+
+	csp := section.Headers[0]
+	assert.Equal(t, "Content-Security-Policy", csp.Name)
+	assert.Equal(t, "script-src 'self' 'unsafe-inline'", csp.Value)
+	assert.Equal(t, model.ResultWarning, csp.Result)
+	assert.Contains(t, csp.Message, "meta tag")
+}
+
+func Test_security_check_should_fail_the_deprecated_tls_versions(t *testing.T) {
+	analysis, err := runChecks(&model.DownloadedWebpage{
+		Url:     "https://www.test.com",
+		TLS:     &model.TLS{Version: "TLS 1.0", CertificateExpires: time.Now().Add(-time.Hour)},
+		Content: []byte(`<html></html>`),
+	}, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+
+	assert.Equal(t, model.ResultFail, section.TLS.Result)
+	assert.Contains(t, section.TLS.Message, "deprecated")
+}
+
+func Test_download_should_describe_the_tls_connection(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html></html>`))
+	}))
+	defer srv.Close()
+
+	sut := NewDownloader(&webClient{client: srv.Client()})
+	page, err := sut.Download(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+	require.NotNil(t, page.TLS)
+	assert.Contains(t, page.TLS.Version, "TLS 1.")
+	assert.NotEmpty(t, page.TLS.CipherSuite)
+	assert.True(t, page.TLS.CertificateExpires.After(time.Now()))
+
+	analysis, err := runChecks(page, newSecurityCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SecuritySection](t, analysis, "security")
+	require.NotNil(t, section.TLS)
+	assert.Equal(t, page.TLS.Version, section.TLS.Version)
+}
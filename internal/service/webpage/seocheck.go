@@ -0,0 +1,270 @@
+package webpage
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+)
+
+const (
+	minTitleLength       = 30
+	maxTitleLength       = 60
+	minDescriptionLength = 70
+	maxDescriptionLength = 160
+
+	// errorPenalty and warningPenalty are the points an issue takes off the SEO score.
+	errorPenalty   = 15
+	warningPenalty = 5
+)
+
+// requiredOpenGraph are the properties every Open Graph page needs, https://ogp.me/#metadata.
+var requiredOpenGraph = []string{"og:title", "og:type", "og:image", "og:url"}
+
+// seoCheck audits the metadata of the <head>, the <meta> and <link> elements of the <body> are ignored.
+type seoCheck struct {
+	inBody       bool
+	descriptions []*seoTag
+	canonicals   []*seoTag
+	robots       []*seoTag
+	hreflangs    []*seoTag
+	openGraph    map[string]string
+	twitterCard  map[string]string
+}
+
+type seoTag struct {
+	value string
+	lang  string
+	line  int
+}
+
+func newSeoCheck() Check {
+	return &seoCheck{
+		openGraph:   map[string]string{},
+		twitterCard: map[string]string{},
+	}
+}
+
+func (c *seoCheck) Name() string {
+	return "seo"
+}
+
+func (c *seoCheck) Token(t *Token) {
+	if !t.IsStartTag() || c.inBody {
+		return
+	}
+
+	switch t.Data {
+	case "body":
+		c.inBody = true
+	case "meta":
+		name, _ := t.AttrValue("name")
+		name = strings.ToLower(strings.TrimSpace(name))
+		property, _ := t.AttrValue("property")
+		property = strings.ToLower(strings.TrimSpace(property))
+		content, _ := t.AttrValue("content")
+		content = strings.TrimSpace(content)
+
+		switch {
+		case name == "description":
+			c.descriptions = append(c.descriptions, &seoTag{value: content, line: t.Line})
+		case name == "robots":
+			c.robots = append(c.robots, &seoTag{value: content, line: t.Line})
+		case strings.HasPrefix(property, "og:"):
+			setFirst(c.openGraph, property, content)
+		// twitter cards are meant to use name, many pages use property like Open Graph does
+		case strings.HasPrefix(name, "twitter:"):
+			setFirst(c.twitterCard, name, content)
+		case strings.HasPrefix(property, "twitter:"):
+			setFirst(c.twitterCard, property, content)
+		}
+	case "link":
+		rel, _ := t.AttrValue("rel")
+		href, _ := t.AttrValue("href")
+		for _, r := range strings.Fields(strings.ToLower(rel)) {
+			switch r {
+			case "canonical":
+				c.canonicals = append(c.canonicals, &seoTag{value: href, line: t.Line})
+			case "alternate":
+				if lang, ok := t.AttrValue("hreflang"); ok {
+					c.hreflangs = append(c.hreflangs,
+						&seoTag{value: href, lang: strings.TrimSpace(lang), line: t.Line})
+				}
+			}
+		}
+	}
+}
+
+func (c *seoCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	section := &model.SeoSection{
+		Title:       analysis.Title,
+		TitleLength: utf8.RuneCountInString(analysis.Title),
+		Hreflangs:   []*model.Hreflang{},
+		OpenGraph:   c.openGraph,
+		TwitterCard: c.twitterCard,
+		H1Count:     analysis.Headings["h1"],
+	}
+	issues := &issueList{list: []*model.Issue{}}
+
+	switch {
+	case section.Title == "":
+		issues.add("title-missing", model.SeverityError, 0, "the page has no title")
+	case section.TitleLength < minTitleLength:
+		issues.add("title-too-short", model.SeverityWarning, 0,
+			"the title is %d characters long, at least %d are recommended", section.TitleLength, minTitleLength)
+	case section.TitleLength > maxTitleLength:
+		issues.add("title-too-long", model.SeverityWarning, 0,
+			"the title is %d characters long, search engines show about %d", section.TitleLength, maxTitleLength)
+	}
+
+	c.description(section, issues)
+	c.canonical(doc, section, issues)
+	c.robotsDirectives(section, issues)
+	c.alternates(doc, section, issues)
+	c.socialTags(issues)
+
+	switch {
+	case section.H1Count == 0:
+		issues.add("h1-missing", model.SeverityError, 0, "the page has no h1 heading")
+	case section.H1Count > 1:
+		issues.add("h1-multiple", model.SeverityWarning, 0,
+			"the page has %d h1 headings, a single one is recommended", section.H1Count)
+	}
+
+	section.Issues = issues.list
+	section.Score = 100 - errorPenalty*issues.count(model.SeverityError) -
+		warningPenalty*issues.count(model.SeverityWarning)
+	if section.Score < 0 {
+		section.Score = 0
+	}
+	analysis.Sections[c.Name()] = section
+
+	return nil
+}
+
+func (c *seoCheck) description(section *model.SeoSection, issues *issueList) {
+	if len(c.descriptions) == 0 || c.descriptions[0].value == "" {
+		issues.add("description-missing", model.SeverityError, 0, "the page has no meta description")
+		return
+	}
+
+	if len(c.descriptions) > 1 {
+		issues.add("description-multiple", model.SeverityWarning, c.descriptions[1].line,
+			"the page has %d meta descriptions, only the first one is used", len(c.descriptions))
+	}
+
+	section.Description = c.descriptions[0].value
+	section.DescriptionLength = utf8.RuneCountInString(section.Description)
+	switch {
+	case section.DescriptionLength < minDescriptionLength:
+		issues.add("description-too-short", model.SeverityWarning, c.descriptions[0].line,
+			"the meta description is %d characters long, at least %d are recommended",
+			section.DescriptionLength, minDescriptionLength)
+	case section.DescriptionLength > maxDescriptionLength:
+		issues.add("description-too-long", model.SeverityWarning, c.descriptions[0].line,
+			"the meta description is %d characters long, search engines show about %d",
+			section.DescriptionLength, maxDescriptionLength)
+	}
+}
+
+func (c *seoCheck) canonical(doc *Document, section *model.SeoSection, issues *issueList) {
+	if len(c.canonicals) == 0 {
+		issues.add("canonical-missing", model.SeverityWarning, 0, "the page has no canonical link")
+		return
+	}
+
+	if len(c.canonicals) > 1 {
+		// search engines ignore all the canonical links of a page which has more than one
+		issues.add("canonical-multiple", model.SeverityError, c.canonicals[1].line,
+			"the page has %d canonical links", len(c.canonicals))
+	}
+
+	canonical := c.canonicals[0]
+	u, err := doc.Resolve(canonical.value)
+	if err != nil || canonical.value == "" {
+		issues.add("canonical-invalid", model.SeverityError, canonical.line,
+			"the canonical link %q is not a valid url", canonical.value)
+		return
+	}
+
+	section.Canonical = u.String()
+	if !isInternalLink(doc.Url, u) {
+		issues.add("canonical-other-host", model.SeverityInfo, canonical.line,
+			"the canonical link points to another host, %s", u.Hostname())
+	}
+}
+
+func (c *seoCheck) robotsDirectives(section *model.SeoSection, issues *issueList) {
+	values := []string{}
+	for _, r := range c.robots {
+		values = append(values, r.value)
+		for _, d := range strings.Split(strings.ToLower(r.value), ",") {
+			d = strings.TrimSpace(d)
+			// none is the short form of noindex, nofollow
+			if d == "noindex" || d == "none" {
+				issues.add("robots-noindex", model.SeverityWarning, r.line,
+					"the robots meta tag excludes the page from the index")
+			}
+			if d == "nofollow" || d == "none" {
+				issues.add("robots-nofollow", model.SeverityInfo, r.line,
+					"the robots meta tag asks not to follow the links")
+			}
+		}
+	}
+	section.Robots = strings.Join(values, ", ")
+}
+
+func (c *seoCheck) alternates(doc *Document, section *model.SeoSection, issues *issueList) {
+	langs := map[string]bool{}
+	for _, h := range c.hreflangs {
+		u, err := doc.Resolve(h.value)
+		if err != nil || h.value == "" || h.lang == "" {
+			issues.add("hreflang-invalid", model.SeverityWarning, h.line,
+				"the alternate link %q of language %q is not valid", h.value, h.lang)
+			continue
+		}
+
+		lang := strings.ToLower(h.lang)
+		if langs[lang] {
+			issues.add("hreflang-duplicate", model.SeverityWarning, h.line,
+				"the language %q has more than one alternate link", h.lang)
+			continue
+		}
+		langs[lang] = true
+		section.Hreflangs = append(section.Hreflangs, &model.Hreflang{Lang: h.lang, Url: u.String()})
+	}
+
+	if len(langs) > 0 && !langs["x-default"] {
+		issues.add("hreflang-x-default-missing", model.SeverityInfo, 0,
+			"the alternate links have no x-default for the unmatched languages")
+	}
+}
+
+func (c *seoCheck) socialTags(issues *issueList) {
+	missing := []string{}
+	for _, p := range requiredOpenGraph {
+		if c.openGraph[p] == "" {
+			missing = append(missing, p)
+		}
+	}
+
+	switch {
+	case len(c.openGraph) == 0:
+		issues.add("open-graph-missing", model.SeverityWarning, 0, "the page has no Open Graph tags")
+	case len(missing) > 0:
+		issues.add("open-graph-incomplete", model.SeverityInfo, 0,
+			"the Open Graph tags miss %s", strings.Join(missing, ", "))
+	}
+
+	if c.twitterCard["twitter:card"] == "" {
+		issues.add("twitter-card-missing", model.SeverityInfo, 0, "the page has no twitter:card tag")
+	}
+}
+
+// setFirst sets the key of m unless it is set already, the first tag of a property wins.
+func setFirst(m map[string]string, key, value string) {
+	if _, ok := m[key]; !ok {
+		m[key] = value
+	}
+}
@@ -0,0 +1,108 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seoPage is a page of the SEO tests, the SEO check reads the title and the headings of the analysis.
+func seoPage(content string) *model.DownloadedWebpage {
+	return &model.DownloadedWebpage{Url: "https://www.test.com/en/products", Content: []byte(content)}
+}
+
+func issueCodes(issues []*model.Issue) []string {
+	codes := []string{}
+	for _, i := range issues {
+		codes = append(codes, i.Code)
+	}
+
+	return codes
+}
+
+func Test_seo_check_should_extract_the_head_metadata(t *testing.T) {
+	analysis, err := runChecks(seoPage(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<title>Handmade oak furniture for every room | Test</title>
+	<meta name="description"
+		content="Browse handmade oak tables, chairs and shelves, built to order in our workshop and delivered.">
+	<link rel="canonical" href="/en/products">
+	<meta name="robots" content="index, follow">
+	<link rel="alternate" hreflang="en" href="https://www.test.com/en/products">
+	<link rel="alternate" hreflang="de" href="/de/produkte">
+	<link rel="alternate" hreflang="x-default" href="/products">
+	<meta property="og:title" content="Handmade oak furniture">
+	<meta property="og:type" content="website">
+	<meta property="og:image" content="https://www.test.com/oak.png">
+	<meta property="og:url" content="https://www.test.com/en/products">
+	<meta name="twitter:card" content="summary_large_image">
+</head>
+<body>
+	<h1>Oak furniture</h1>
+	<meta name="description" content="ignored, not in the head">
+</body>
+</html>`), newTitleCheck(), newHeadingsCheck(), newSeoCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SeoSection](t, analysis, "seo")
+
+	assert.Equal(t, 100, section.Score)
+	assert.Empty(t, section.Issues)
+	assert.Equal(t, "Handmade oak furniture for every room | Test", section.Title)
+	assert.Equal(t, 44, section.TitleLength)
+	assert.Equal(t, 93, section.DescriptionLength)
+	assert.Equal(t, "https://www.test.com/en/products", section.Canonical)
+	assert.Equal(t, "index, follow", section.Robots)
+	assert.Equal(t, []*model.Hreflang{
+		{Lang: "en", Url: "https://www.test.com/en/products"},
+		{Lang: "de", Url: "https://www.test.com/de/produkte"},
+		{Lang: "x-default", Url: "https://www.test.com/products"},
+	}, section.Hreflangs)
+	assert.Equal(t, "website", section.OpenGraph["og:type"])
+	assert.Equal(t, "summary_large_image", section.TwitterCard["twitter:card"])
+	assert.Equal(t, 1, section.H1Count)
+}
+
+func Test_seo_check_should_report_the_missing_metadata(t *testing.T) {
+	analysis, err := runChecks(seoPage(`<html><head></head><body><p>nothing</p></body></html>`),
+		newTitleCheck(), newHeadingsCheck(), newSeoCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SeoSection](t, analysis, "seo")
+
+	assert.Equal(t, []string{
+		"title-missing", "description-missing", "canonical-missing", "open-graph-missing",
+		"twitter-card-missing", "h1-missing",
+	}, issueCodes(section.Issues))
+	assert.Equal(t, 100-3*errorPenalty-2*warningPenalty, section.Score)
+}
+
+func Test_seo_check_should_report_the_conflicting_metadata(t *testing.T) {
+	analysis, err := runChecks(seoPage(`<html>
+<head>
+<title>Short</title>
+<meta name="description" content="Too short">
+<meta name="description" content="Second">
+<link rel="canonical" href="https://www.test.com/a">
+<link rel="canonical" href="https://www.test.com/b">
+<meta name="robots" content="NOINDEX, nofollow">
+<link rel="alternate" hreflang="fr" href="/fr">
+<link rel="alternate" hreflang="FR" href="/fr-2">
+<meta property="og:title" content="Short">
+</head>
+<body><h1>one</h1><h1>two</h1></body>
+</html>`), newTitleCheck(), newHeadingsCheck(), newSeoCheck())
+	require.NoError(t, err)
+	section := sectionOf[*model.SeoSection](t, analysis, "seo")
+
+	assert.Equal(t, []string{
+		"title-too-short", "description-multiple", "description-too-short", "canonical-multiple",
+		"robots-noindex", "robots-nofollow", "hreflang-duplicate", "hreflang-x-default-missing",
+		"open-graph-incomplete", "twitter-card-missing", "h1-multiple",
+	}, issueCodes(section.Issues))
+	assert.Equal(t, 5, section.Issues[1].Line)
+	assert.Equal(t, 7, section.Issues[3].Line)
+	assert.Equal(t, "the Open Graph tags miss og:type, og:image, og:url", section.Issues[8].Message)
+	assert.Equal(t, model.SeverityError, section.Issues[3].Severity)
+}
@@ -0,0 +1,30 @@
+package webpage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type sitemapUrlSet struct {
+	Urls []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// ParseSitemap returns the page urls listed in a sitemap.xml, sitemap index files are not followed.
+func ParseSitemap(content []byte) ([]string, error) {
+	set := &sitemapUrlSet{}
+	if err := xml.Unmarshal(content, set); err != nil {
+		return nil, fmt.Errorf("unable to parse the sitemap, %v", err)
+	}
+
+	urls := []string{}
+	for _, u := range set.Urls {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			urls = append(urls, loc)
+		}
+	}
+
+	return urls, nil
+}
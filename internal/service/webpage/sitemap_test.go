@@ -0,0 +1,30 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parse_sitemap_should_return_the_page_urls(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://www.test.com/</loc><lastmod>2023-01-01</lastmod></url>
+	<url><loc>
+		https://www.test.com/about
+	</loc></url>
+	<url><loc></loc></url>
+</urlset>`
+
+	urls, err := ParseSitemap([]byte(content))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://www.test.com/", "https://www.test.com/about"}, urls)
+}
+
+func Test_parse_sitemap_should_throw_an_error_for_invalid_xml(t *testing.T) {
+	urls, err := ParseSitemap([]byte("<html><body>not found"))
+
+	assert.Error(t, err)
+	assert.Nil(t, urls)
+}
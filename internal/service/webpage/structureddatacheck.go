@@ -0,0 +1,338 @@
+package webpage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"golang.org/x/net/html"
+)
+
+// voidElements have no end tag, they never hold the items or the text of a property.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// structuredDataCheck extracts the JSON-LD blocks, the Microdata items and the basic RDFa (typeof and
+// property) of the page, every top level entity needs a type.
+type structuredDataCheck struct {
+	jsonLd    *Token
+	jsonText  strings.Builder
+	jsonLds   []*jsonLdBlock
+	microdata *itemTracker
+	rdfa      *itemTracker
+}
+
+type jsonLdBlock struct {
+	t    *Token
+	text string
+}
+
+func newStructuredDataCheck() Check {
+	return &structuredDataCheck{
+		microdata: &itemTracker{
+			format: model.FormatMicrodata,
+			scope: func(t *Token) (string, bool) {
+				if _, ok := t.AttrValue("itemscope"); !ok {
+					return "", false
+				}
+				typ, _ := t.AttrValue("itemtype")
+				return strings.TrimSpace(typ), true
+			},
+			prop:  "itemprop",
+			value: microdataValue,
+		},
+		rdfa: &itemTracker{
+			format: model.FormatRdfa,
+			scope: func(t *Token) (string, bool) {
+				typ, ok := t.AttrValue("typeof")
+				return strings.TrimSpace(typ), ok
+			},
+			prop:  "property",
+			value: rdfaValue,
+		},
+	}
+}
+
+func (c *structuredDataCheck) Name() string {
+	return "structuredData"
+}
+
+func (c *structuredDataCheck) Token(t *Token) {
+	switch {
+	case t.Type == html.StartTagToken && t.Data == "script":
+		if typ, _ := t.AttrValue("type"); strings.EqualFold(strings.TrimSpace(typ), "application/ld+json") {
+			c.jsonLd = t
+			c.jsonText.Reset()
+		}
+	case t.Type == html.TextToken && c.jsonLd != nil:
+		c.jsonText.WriteString(t.Data)
+	case t.Type == html.EndTagToken && t.Data == "script" && c.jsonLd != nil:
+		c.jsonLds = append(c.jsonLds, &jsonLdBlock{t: c.jsonLd, text: c.jsonText.String()})
+		c.jsonLd = nil
+	}
+
+	c.microdata.token(t)
+	c.rdfa.token(t)
+}
+
+func (c *structuredDataCheck) Finish(ctx context.Context, doc *Document, analysis *model.Analysis) error {
+	section := &model.StructuredDataSection{Entities: []*model.Entity{}}
+	issues := &issueList{list: []*model.Issue{}}
+
+	for _, b := range c.jsonLds {
+		entities, err := parseJsonLd(b.text, b.t.Line)
+		if err != nil {
+			issues.addAt("json-ld-invalid", model.SeverityError, b.t, "the JSON-LD block is not valid, %v", err)
+			continue
+		}
+		section.Entities = append(section.Entities, entities...)
+	}
+
+	c.microdata.closeAll()
+	c.rdfa.closeAll()
+	section.Entities = append(section.Entities, c.microdata.entities...)
+	section.Entities = append(section.Entities, c.rdfa.entities...)
+
+	for _, e := range section.Entities {
+		if e.Type == "" {
+			issues.add("type-missing", model.SeverityError, e.Line, "the %s entity has no type", e.Format)
+		}
+	}
+
+	section.Issues = issues.list
+	analysis.Sections[c.Name()] = section
+
+	return nil
+}
+
+// parseJsonLd returns the entities of a JSON-LD block, a single object, an array of objects or an
+// object with a @graph.
+func parseJsonLd(text string, line int) ([]*model.Entity, error) {
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return nil, err
+	}
+
+	objects := []any{v}
+	if a, ok := v.([]any); ok {
+		objects = a
+	} else if o, ok := v.(map[string]any); ok {
+		if graph, ok := o["@graph"].([]any); ok {
+			objects = graph
+		}
+	}
+
+	entities := []*model.Entity{}
+	for _, o := range objects {
+		properties, ok := o.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object, found %T", o)
+		}
+
+		entity := &model.Entity{Format: model.FormatJsonLd, Properties: properties, Line: line}
+		switch typ := properties["@type"].(type) {
+		case string:
+			entity.Type = strings.TrimSpace(typ)
+		case []any:
+			types := []string{}
+			for _, t := range typ {
+				if s, ok := t.(string); ok {
+					types = append(types, s)
+				}
+			}
+			entity.Type = strings.Join(types, " ")
+		}
+		delete(properties, "@type")
+		entities = append(entities, entity)
+	}
+
+	return entities, nil
+}
+
+// itemTracker builds the items of an attribute based format, Microdata or RDFa, from the tokens.
+// The open elements are tracked to know the item a property belongs to and the text of a property.
+type itemTracker struct {
+	format string
+	// scope returns the type of the item the element starts
+	scope func(t *Token) (string, bool)
+	// prop is the attribute naming the properties of the element
+	prop string
+	// value returns the value of a property held by an attribute of the element
+	value func(t *Token) (string, bool)
+
+	stack     []*itemElement
+	textProps int
+	entities  []*model.Entity
+}
+
+type itemElement struct {
+	name string
+	item *model.Entity
+	// the properties of item whose value is the text of the element
+	textItem  *model.Entity
+	textProps []string
+	text      strings.Builder
+}
+
+func (it *itemTracker) token(t *Token) {
+	switch {
+	case t.IsStartTag():
+		it.startTag(t)
+	case t.Type == html.EndTagToken:
+		it.endTag(t.Data)
+	case t.Type == html.TextToken && it.textProps > 0:
+		for _, e := range it.stack {
+			if e.textItem != nil {
+				e.text.WriteString(t.Data)
+			}
+		}
+	}
+}
+
+func (it *itemTracker) startTag(t *Token) {
+	props := []string{}
+	if p, ok := t.AttrValue(it.prop); ok {
+		props = strings.Fields(p)
+	}
+
+	parent := it.current()
+	e := &itemElement{name: t.Data}
+	if typ, ok := it.scope(t); ok {
+		e.item = &model.Entity{Format: it.format, Type: typ, Properties: map[string]any{}, Line: t.Line}
+		if parent != nil && len(props) > 0 {
+			for _, p := range props {
+				addProperty(parent, p, e.item)
+			}
+		} else {
+			it.entities = append(it.entities, e.item)
+		}
+	} else if parent != nil && len(props) > 0 {
+		if v, ok := it.value(t); ok {
+			for _, p := range props {
+				addProperty(parent, p, v)
+			}
+		} else {
+			e.textItem = parent
+			e.textProps = props
+		}
+	}
+
+	if voidElements[t.Data] || t.Type == html.SelfClosingTagToken {
+		// an element without content has an empty text
+		for _, p := range e.textProps {
+			addProperty(e.textItem, p, "")
+		}
+		return
+	}
+
+	if e.textItem != nil {
+		it.textProps++
+	}
+	it.stack = append(it.stack, e)
+}
+
+// endTag closes the element and the elements left open inside it, an end tag without a start tag
+// is ignored.
+func (it *itemTracker) endTag(name string) {
+	for i := len(it.stack) - 1; i >= 0; i-- {
+		if it.stack[i].name != name {
+			continue
+		}
+
+		for j := len(it.stack) - 1; j >= i; j-- {
+			it.close(it.stack[j])
+		}
+		it.stack = it.stack[:i]
+		return
+	}
+}
+
+func (it *itemTracker) closeAll() {
+	for j := len(it.stack) - 1; j >= 0; j-- {
+		it.close(it.stack[j])
+	}
+	it.stack = nil
+}
+
+func (it *itemTracker) close(e *itemElement) {
+	if e.textItem == nil {
+		return
+	}
+
+	text := strings.Join(strings.Fields(e.text.String()), " ")
+	for _, p := range e.textProps {
+		addProperty(e.textItem, p, text)
+	}
+	e.textItem = nil
+	it.textProps--
+}
+
+// current returns the innermost open item.
+func (it *itemTracker) current() *model.Entity {
+	for i := len(it.stack) - 1; i >= 0; i-- {
+		if it.stack[i].item != nil {
+			return it.stack[i].item
+		}
+	}
+
+	return nil
+}
+
+// addProperty sets the property of the entity, a repeated property becomes a list of values.
+func addProperty(e *model.Entity, name string, value any) {
+	existing, ok := e.Properties[name]
+	if !ok {
+		e.Properties[name] = value
+		return
+	}
+
+	if values, ok := existing.([]any); ok {
+		e.Properties[name] = append(values, value)
+		return
+	}
+	e.Properties[name] = []any{existing, value}
+}
+
+// microdataValue returns the value of a Microdata property held by an attribute, following
+// https://html.spec.whatwg.org/multipage/microdata.html#values.
+func microdataValue(t *Token) (string, bool) {
+	key := ""
+	switch t.Data {
+	case "meta":
+		key = "content"
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		key = "src"
+	case "a", "area", "link":
+		key = "href"
+	case "object":
+		key = "data"
+	case "data", "meter":
+		key = "value"
+	case "time":
+		key = "datetime"
+	default:
+		return "", false
+	}
+
+	v, ok := t.AttrValue(key)
+	if !ok && t.Data == "time" {
+		return "", false
+	}
+
+	return strings.TrimSpace(v), true
+}
+
+// rdfaValue returns the value of a RDFa property held by an attribute, content wins over the links.
+func rdfaValue(t *Token) (string, bool) {
+	for _, key := range []string{"content", "resource", "href", "src", "datetime"} {
+		if v, ok := t.AttrValue(key); ok {
+			return strings.TrimSpace(v), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,108 @@
+package webpage
+
+import (
+	"testing"
+
+	"github.com/DiLRandI/web-analyser/internal/service/webpage/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_structured_data_check_should_extract_the_json_ld_entities(t *testing.T) {
+	analysis, err := runCheck(newStructuredDataCheck(), "https://www.test.com", `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Oak table",
+ "offers": {"@type": "Offer", "price": "499.00"}}
+</script>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+	{"@type": ["Article", "NewsArticle"], "headline": "New tables"},
+	{"name": "no type"}
+]}
+</script>
+<script type="application/ld+json">{"@type": "Broken",</script>
+<script>var notJsonLd = 1;</script>
+</head></html>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.StructuredDataSection](t, analysis, "structuredData")
+
+	require.Len(t, section.Entities, 3)
+	assert.Equal(t, &model.Entity{
+		Format: model.FormatJsonLd,
+		Type:   "Product",
+		Properties: map[string]any{
+			"@context": "https://schema.org",
+			"name":     "Oak table",
+			"offers":   map[string]any{"@type": "Offer", "price": "499.00"},
+		},
+		Line: 2,
+	}, section.Entities[0])
+	assert.Equal(t, "Article NewsArticle", section.Entities[1].Type)
+	assert.Equal(t, "", section.Entities[2].Type)
+
+	require.Len(t, section.Issues, 2)
+	assert.Equal(t, "json-ld-invalid", section.Issues[0].Code)
+	assert.Equal(t, 12, section.Issues[0].Line)
+	assert.Equal(t, `<script type="application/ld+json">`, section.Issues[0].Element)
+	assert.Equal(t, "type-missing", section.Issues[1].Code)
+	assert.Equal(t, 6, section.Issues[1].Line)
+}
+
+func Test_structured_data_check_should_extract_the_microdata_items(t *testing.T) {
+	analysis, err := runCheck(newStructuredDataCheck(), "https://www.test.com",
+		`<div itemscope itemtype="https://schema.org/Product">
+	<h1 itemprop="name">Oak <b>table</b></h1>
+	<img itemprop="image" src="/oak.png">
+	<p>Colours: <span itemprop="color">brown</span>, <span itemprop="color">black</span></p>
+	<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+		<meta itemprop="priceCurrency" content="EUR">
+		<span itemprop="price">499</span>
+	</div>
+	<p itemprop="description">Unclosed paragraph
+</div>
+<div itemscope><span itemprop="name">untyped</span></div>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.StructuredDataSection](t, analysis, "structuredData")
+
+	require.Len(t, section.Entities, 2)
+	assert.Equal(t, &model.Entity{
+		Format: model.FormatMicrodata,
+		Type:   "https://schema.org/Product",
+		Properties: map[string]any{
+			"name":        "Oak table",
+			"image":       "/oak.png",
+			"color":       []any{"brown", "black"},
+			"description": "Unclosed paragraph",
+			"offers": &model.Entity{
+				Format:     model.FormatMicrodata,
+				Type:       "https://schema.org/Offer",
+				Properties: map[string]any{"priceCurrency": "EUR", "price": "499"},
+				Line:       5,
+			},
+		},
+		Line: 1,
+	}, section.Entities[0])
+	assert.Equal(t, []string{"type-missing"}, issueCodes(section.Issues))
+	assert.Equal(t, 11, section.Issues[0].Line)
+}
+
+func Test_structured_data_check_should_extract_the_rdfa_entities(t *testing.T) {
+	analysis, err := runCheck(newStructuredDataCheck(), "https://www.test.com",
+		`<head><meta property="og:title" content="not an entity"></head>
+<div vocab="https://schema.org/" typeof="Person">
+	<span property="name">Jane Doe</span>
+	<a property="url" href="https://jane.test.com">home</a>
+	<div property="address" typeof="PostalAddress"><span property="addressLocality">Colombo</span></div>
+</div>`)
+	require.NoError(t, err)
+	section := sectionOf[*model.StructuredDataSection](t, analysis, "structuredData")
+
+	require.Len(t, section.Entities, 1)
+	assert.Equal(t, model.FormatRdfa, section.Entities[0].Format)
+	assert.Equal(t, "Person", section.Entities[0].Type)
+	assert.Equal(t, "Jane Doe", section.Entities[0].Properties["name"])
+	assert.Equal(t, "https://jane.test.com", section.Entities[0].Properties["url"])
+	assert.Equal(t, map[string]any{"addressLocality": "Colombo"},
+		section.Entities[0].Properties["address"].(*model.Entity).Properties)
+	assert.Empty(t, section.Issues)
+}
@@ -1,9 +1,126 @@
 package webpage
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
 )
 
+var (
+	ResponseTooLargeErr = errors.New("response body exceeds the maximum allowed size")
+)
+
+// WebClient sends the http requests of the analyser and the downloader, every request is bound
+// to the caller ctx and to the client policies.
 type WebClient interface {
-	Get(url string) (resp *http.Response, err error)
+	Get(ctx context.Context, url string) (resp *http.Response, err error)
+	// Do sends a prepared request, the ctx of the request is used.
+	Do(req *http.Request) (resp *http.Response, err error)
+}
+
+type ClientConfig struct {
+	// Timeout bounds a single request including the redirects and reading the body, 0 means no timeout.
+	Timeout   time.Duration
+	UserAgent string
+	// Headers are added to every request, unless the request sets them.
+	Headers map[string]string
+	// ProxyUrl routes the requests through a proxy, when empty the proxy environment variables are used.
+	ProxyUrl string
+	// MaxResponseSize limits the bytes read from a response body, 0 means unlimited.
+	MaxResponseSize int64
+}
+
+type webClient struct {
+	client *http.Client
+	config ClientConfig
+}
+
+func NewWebClient(config *ClientConfig) (WebClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.ProxyUrl != "" {
+		proxyUrl, err := url.Parse(config.ProxyUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %q, %v", config.ProxyUrl, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	return &webClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+		config: *config,
+	}, nil
+}
+
+func (c *webClient) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+func (c *webClient) Do(req *http.Request) (*http.Response, error) {
+	if c.config.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	for k, v := range c.config.Headers {
+		if req.Header.Get(k) == "" {
+			req.Header.Set(k, v)
+		}
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.config.MaxResponseSize > 0 {
+		if res.ContentLength > c.config.MaxResponseSize {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("%w, content length %d", ResponseTooLargeErr, res.ContentLength)
+		}
+
+		res.Body = &limitedBody{body: res.Body, remaining: c.config.MaxResponseSize}
+	}
+
+	return res, nil
+}
+
+// limitedBody fails the read once more than the allowed bytes are read, unlike io.LimitReader
+// which silently truncates the content.
+type limitedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ResponseTooLargeErr
+	}
+
+	// read one byte over the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ResponseTooLargeErr
+	}
+
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.body.Close()
 }
@@ -0,0 +1,121 @@
+package webpage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_web_client_should_apply_the_user_agent_and_headers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.UserAgent()+"|"+r.Header.Get("X-Test")+"|"+r.Header.Get("Accept-Language"))
+	}))
+	defer srv.Close()
+
+	sut, err := NewWebClient(&ClientConfig{
+		UserAgent: "web-analyser/test",
+		Headers:   map[string]string{"X-Test": "configured", "Accept-Language": "en"},
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept-Language", "de")
+	res, err := sut.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "web-analyser/test|configured|de", string(body))
+}
+
+func Test_web_client_should_time_out_slow_requests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	sut, err := NewWebClient(&ClientConfig{Timeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = sut.Get(context.Background(), srv.URL)
+
+	assert.Error(t, err)
+}
+
+func Test_web_client_should_stop_when_the_ctx_is_cancelled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	sut, err := NewWebClient(&ClientConfig{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err = sut.Get(ctx, srv.URL)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_web_client_should_reject_responses_over_the_max_size(t *testing.T) {
+	content := strings.Repeat("a", 20)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// flushing before writing the content drops the Content-Length header
+			w.(http.Flusher).Flush()
+		}
+		_, _ = io.WriteString(w, content)
+	}))
+	defer srv.Close()
+
+	sut, err := NewWebClient(&ClientConfig{MaxResponseSize: 10})
+	require.NoError(t, err)
+
+	_, err = sut.Get(context.Background(), srv.URL)
+	assert.ErrorIs(t, err, ResponseTooLargeErr)
+
+	res, err := sut.Get(context.Background(), srv.URL+"/chunked")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	_, err = io.ReadAll(res.Body)
+	assert.ErrorIs(t, err, ResponseTooLargeErr)
+}
+
+func Test_web_client_should_read_a_body_of_exactly_the_max_size(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		_, _ = io.WriteString(w, strings.Repeat("a", 10))
+	}))
+	defer srv.Close()
+
+	sut, err := NewWebClient(&ClientConfig{MaxResponseSize: 10})
+	require.NoError(t, err)
+
+	res, err := sut.Get(context.Background(), srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.Len(t, body, 10)
+}
+
+func Test_new_web_client_should_throw_an_error_for_invalid_proxy_url(t *testing.T) {
+	sut, err := NewWebClient(&ClientConfig{ProxyUrl: "://invalid"})
+
+	assert.Error(t, err)
+	assert.Nil(t, sut)
+}
@@ -19,7 +19,38 @@ func (m *ProcessorMock) GetProcessResultFor(ctx context.Context, id int64) (*dto
 	args := m.Called(ctx, id)
 	return args.Get(0).(*dto.ResultResponse), args.Error(1)
 }
-func (m *ProcessorMock) GetProcessResults(ctx context.Context) ([]*dto.ResultResponse, error) {
+func (m *ProcessorMock) GetProcessResults(
+	ctx context.Context, q *dto.ResultsQuery,
+) ([]*dto.ResultResponse, int, error) {
+	args := m.Called(ctx, q)
+	return args.Get(0).([]*dto.ResultResponse), args.Int(1), args.Error(2)
+}
+func (m *ProcessorMock) GetProcessLinksFor(
+	ctx context.Context, id int64, q *dto.LinksQuery,
+) ([]*dto.LinkResponse, int, error) {
+	args := m.Called(ctx, id, q)
+	return args.Get(0).([]*dto.LinkResponse), args.Int(1), args.Error(2)
+}
+func (m *ProcessorMock) Start(ctx context.Context) error {
 	args := m.Called(ctx)
-	return args.Get(0).([]*dto.ResultResponse), args.Error(1)
+	return args.Error(0)
+}
+func (m *ProcessorMock) Stop() {
+	m.Called()
+}
+func (m *ProcessorMock) CancelProcess(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *ProcessorMock) RemoveProcessResult(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *ProcessorMock) ProcessSite(ctx context.Context, req *dto.CrawlRequest) (*dto.AnalysesResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(*dto.AnalysesResponse), args.Error(1)
+}
+func (m *ProcessorMock) GetSiteResultFor(ctx context.Context, id int64) (*dto.CrawlResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*dto.CrawlResponse), args.Error(1)
 }
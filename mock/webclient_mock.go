@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/stretchr/testify/mock"
@@ -10,7 +11,12 @@ type WebClientMock struct {
 	mock.Mock
 }
 
-func (m *WebClientMock) Get(url string) (resp *http.Response, err error) {
+func (m *WebClientMock) Get(ctx context.Context, url string) (resp *http.Response, err error) {
 	args := m.Called(url)
 	return args.Get(0).(*http.Response), args.Error(1)
 }
+
+func (m *WebClientMock) Do(req *http.Request) (resp *http.Response, err error) {
+	args := m.Called(req.Method, req.URL.String())
+	return args.Get(0).(*http.Response), args.Error(1)
+}